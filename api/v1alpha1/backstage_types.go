@@ -15,6 +15,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
@@ -25,10 +27,25 @@ type BackstageConditionType string
 
 const (
 	BackstageConditionTypeDeployed BackstageConditionType = "Deployed"
+	// ImagesPinned condition is set if Spec.PinImageDigests is enabled
+	BackstageConditionTypeImagesPinned BackstageConditionType = "ImagesPinned"
 
 	BackstageConditionReasonDeployed   BackstageConditionReason = "Deployed"
 	BackstageConditionReasonFailed     BackstageConditionReason = "DeployFailed"
 	BackstageConditionReasonInProgress BackstageConditionReason = "DeployInProgress"
+	BackstageConditionReasonSuspended  BackstageConditionReason = "Suspended"
+	BackstageConditionReasonPaused     BackstageConditionReason = "ReconcilePaused"
+
+	BackstageConditionReasonImagesPinned       BackstageConditionReason = "ImagesPinned"
+	BackstageConditionReasonImageResolveFailed BackstageConditionReason = "ImageResolveFailed"
+)
+
+type DbDeletionPolicy string
+
+const (
+	DbDeletionPolicyRetain   DbDeletionPolicy = "Retain"
+	DbDeletionPolicyDelete   DbDeletionPolicy = "Delete"
+	DbDeletionPolicySnapshot DbDeletionPolicy = "Snapshot"
 )
 
 // BackstageSpec defines the desired state of Backstage
@@ -43,6 +60,50 @@ type BackstageSpec struct {
 
 	// Configuration for database access. Optional.
 	Database *Database `json:"database,omitempty"`
+
+	// Name of the Operator default configuration profile, for example "upstream".
+	// Each configuration key is taken from the selected profile if defined there,
+	// from the base default configuration otherwise. Optional, the base default configuration is used if not set.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Profile string `json:"profile,omitempty"`
+
+	// If true, the Operator resolves the image tags of Backstage and local database containers to digests
+	// and pins the images to them, so all the replicas run exactly the same build.
+	// Resolved digests are recorded in the status and re-resolved when the Backstage CR or the default configuration changes.
+	// Images which can not be resolved (for example, of registries requiring authentication) are left unpinned,
+	// as reported by ImagesPinned condition.
+	// +optional
+	PinImageDigests bool `json:"pinImageDigests,omitempty"`
+
+	// If true, the Backstage Deployment and local database StatefulSet are scaled down to zero replicas,
+	// all the configuration objects and database data are kept.
+	// The same can be requested with rhdh.redhat.com/suspend: "true" annotation.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Schedule of the time windows Backstage is running in, outside of them Backstage is suspended
+	// the same way as with Suspend. Optional, Backstage is running all the time if not set.
+	// +optional
+	Schedule *Schedule `json:"schedule,omitempty"`
+}
+
+// Schedule defines the time windows Backstage is running in, each window starts
+// at the time matching Start expression and ends at the next time matching Stop expression
+type Schedule struct {
+	// Cron expression (standard 5 fields format, for example "0 8 * * 1-5") of the times Backstage is started
+	// Descriptors (like @daily) and time zone prefixes (TZ=, CRON_TZ=) are not supported, see TimeZone.
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+
+	// Cron expression (standard 5 fields format, for example "0 18 * * 1-5") of the times Backstage is stopped
+	// Descriptors (like @daily) and time zone prefixes (TZ=, CRON_TZ=) are not supported, see TimeZone.
+	// +kubebuilder:validation:MinLength=1
+	Stop string `json:"stop"`
+
+	// Name of the time zone (for example "Europe/Prague") the expressions are evaluated in, UTC if not set.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 type RuntimeConfig struct {
@@ -68,6 +129,49 @@ type Database struct {
 	// "POSTGRESQL_ADMIN_PASSWORD": "rl4s3Fh4ng3M4"
 	// "POSTGRES_HOST": "backstage-psql-bs1"  # For local database, set to "backstage-psql-<CR name>".
 	AuthSecretName string `json:"authSecretName,omitempty"`
+
+	// Storage configuration of the local PostgreSQL DB. Optional.
+	// Ignored if EnableLocalDb is false.
+	// +optional
+	Storage *DbStorage `json:"storage,omitempty"`
+
+	// What happens to the local database PersistentVolumeClaims and generated DB Secret when the Backstage CR is deleted.
+	// Retain keeps them, so an instance recreated with the same name reuses the data.
+	// Delete removes them.
+	// Snapshot creates a VolumeSnapshot of each PersistentVolumeClaim, waits until all of them are ready to use,
+	// then removes the PersistentVolumeClaims and keeps the DB Secret, so the snapshot can be restored with the same credentials.
+	// The Backstage CR deletion fails if any VolumeSnapshot reports an error.
+	// If the VolumeSnapshot API is not available in the cluster, Snapshot behaves as Retain.
+	// Ignored if EnableLocalDb is false.
+	// +optional
+	// +kubebuilder:default=Retain
+	// +kubebuilder:validation:Enum=Retain;Delete;Snapshot
+	DeletionPolicy DbDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+type DbStorage struct {
+	// Size of the local database volume, for example "5Gi".
+	// Increasing the size of an existing instance expands its PersistentVolumeClaims,
+	// which requires a StorageClass allowing volume expansion. Shrinking is not supported.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Name of the StorageClass used for the local database PersistentVolumeClaims.
+	// The cluster default StorageClass is used if not set.
+	// Applied to newly created PersistentVolumeClaims only.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Access modes of the local database PersistentVolumeClaims.
+	// Applied to newly created PersistentVolumeClaims only.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// Whether the local database PersistentVolumeClaims are retained or deleted when the StatefulSet is deleted.
+	// Requires the StatefulSetAutoDeletePVC feature gate, enabled by default since Kubernetes 1.27.
+	// +optional
+	// +kubebuilder:validation:Enum=Retain;Delete
+	WhenDeleted string `json:"whenDeleted,omitempty"`
 }
 
 type Application struct {
@@ -104,17 +208,56 @@ type Application struct {
 	//+kubebuilder:default=1
 	Replicas *int32 `json:"replicas,omitempty"`
 
-	// Custom image to use in all containers (including Init Containers).
+	// Custom image to use in the containers running Backstage image, i.e. the ones with "backend" and "dynamic-plugins-installer" roles.
 	// It is your responsibility to make sure the image is from trusted sources and has been validated for security compliance
 	// +optional
 	Image *string `json:"image,omitempty"`
 
+	// Custom images to use in the containers, keyed by the container role, for example "backend" or "dynamic-plugins-installer".
+	// The role of the container is defined with "container-role.rhdh.redhat.com/<container-name>" annotation (or label) of the Pod template,
+	// by default the first container has "backend" role and "install-dynamic-plugins" init container has "dynamic-plugins-installer" role.
+	// Takes precedence over the Image.
+	// It is your responsibility to make sure the images are from trusted sources and have been validated for security compliance
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
 	// Image Pull Secrets to use in all containers (including Init Containers)
 	// +optional
 	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
 
 	// Route configuration. Used for OpenShift only.
 	Route *Route `json:"route,omitempty"`
+
+	// Additional containers (sidecars) added to the Backstage Pod as is, after all the containers configured by the Operator.
+	// They can mount the volumes created by the Operator.
+	// +optional
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+
+	// Additional init containers added to the Backstage Pod as is, after all the init containers configured by the Operator.
+	// They can mount the volumes created by the Operator.
+	// +optional
+	ExtraInitContainers []corev1.Container `json:"extraInitContainers,omitempty"`
+
+	// Additional volumes (for example PersistentVolumeClaims, emptyDirs, CSI or projected volumes) added to the Backstage Pod.
+	// Names must not collide with the volumes created by the Operator.
+	// +optional
+	ExtraVolumes []corev1.Volume `json:"extraVolumes,omitempty"`
+
+	// Additional volume mounts of the main Backstage container.
+	// Each mount has to refer either ExtraVolumes or the volume created by the Operator.
+	// +optional
+	ExtraVolumeMounts []corev1.VolumeMount `json:"extraVolumeMounts,omitempty"`
+
+	// Additional trusted CA certificates, merged into a single bundle
+	// which is used by Backstage and dynamic plugins installation (NODE_EXTRA_CA_CERTS).
+	// +optional
+	TrustedCA *TrustedCA `json:"trustedCA,omitempty"`
+
+	// HTTP(S) proxy configuration of the Backstage Pod containers.
+	// By default, the proxy configuration of the cluster (OpenShift Proxy) or the Operator (HTTP_PROXY, HTTPS_PROXY, NO_PROXY env variables) is used,
+	// the values specified here take precedence.
+	// +optional
+	Proxy *Proxy `json:"proxy,omitempty"`
 }
 
 type AppConfig struct {
@@ -169,6 +312,45 @@ type ExtraEnvs struct {
 	Envs []Env `json:"envs,omitempty"`
 }
 
+type TrustedCA struct {
+	// List of references to ConfigMaps containing PEM encoded CA certificates.
+	// If a key is not specified, all keys of the ConfigMap are added to the bundle.
+	// +optional
+	ConfigMaps []ObjectKeyRef `json:"configMaps,omitempty"`
+
+	// List of references to Secrets containing PEM encoded CA certificates.
+	// If a key is not specified, all keys of the Secret are added to the bundle,
+	// so specify the key for the Secrets containing other data (like tls.key of TLS Secret).
+	// +optional
+	Secrets []ObjectKeyRef `json:"secrets,omitempty"`
+
+	// Used for OpenShift only. If true, the ConfigMap labeled with config.openshift.io/inject-trusted-cabundle
+	// is created, so the cluster-wide trusted CA bundle (including the proxy CA) injected into it is added to the bundle.
+	// +optional
+	InjectClusterCABundle bool `json:"injectClusterCABundle,omitempty"`
+}
+
+type Proxy struct {
+	// Control the propagation of the proxy configuration to the Backstage Pod containers.
+	// Defaults to true.
+	// +optional
+	//+kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// URL of the proxy for HTTP requests (HTTP_PROXY)
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// URL of the proxy for HTTPS requests (HTTPS_PROXY)
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// Comma-separated list of hosts, domains and CIDRs which are not proxied (NO_PROXY).
+	// Backstage and local database services are added automatically.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
 type ObjectKeyRef struct {
 	// Name of the object
 	// We support only ConfigMaps and Secrets.
@@ -178,8 +360,26 @@ type ObjectKeyRef struct {
 	// Key in the object
 	// +optional
 	Key string `json:"key,omitempty"`
+
+	// How the files are mounted, one of:
+	// SubPath (default) - each file is mounted to the mount path separately, it is not updated when the object changes;
+	// Directory - the object is mounted as a <mount path>/<object name> directory, files are updated when the object changes;
+	// Projected - all the objects of the list with Projected mode are merged into a single <mount path>/<list name> directory
+	// (app-config or extra-files), files are updated when the objects change, so the file names must be unique across the objects.
+	// Not used for environment variables.
+	// +optional
+	// +kubebuilder:validation:Enum=SubPath;Directory;Projected
+	MountMode MountMode `json:"mountMode,omitempty"`
 }
 
+type MountMode string
+
+const (
+	MountModeSubPath   MountMode = "SubPath"
+	MountModeDirectory MountMode = "Directory"
+	MountModeProjected MountMode = "Projected"
+)
+
 type Env struct {
 	// Name of the environment variable
 	//+kubebuilder:validation:Required
@@ -195,6 +395,56 @@ type BackstageStatus struct {
 	// Conditions is the list of conditions describing the state of the runtime
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ExtraObjects is the list of additional objects applied from extra-*.yaml keys of RawRuntimeConfig ConfigMap,
+	// the objects removed from the configuration are deleted
+	// +optional
+	ExtraObjects []ExtraObjectRef `json:"extraObjects,omitempty"`
+
+	// ImageDigests is the list of images pinned to digests, if Spec.PinImageDigests is enabled
+	// +optional
+	ImageDigests []ImageDigest `json:"imageDigests,omitempty"`
+
+	// ImageDigestsSource is the hash of the Backstage CR generation and the Operator default configuration
+	// the ImageDigests are resolved for, the digests are resolved again when it changes
+	// +optional
+	ImageDigestsSource string `json:"imageDigestsSource,omitempty"`
+
+	// BackendSecretRotation is the value of rhdh.redhat.com/rotate-backend-secret annotation
+	// the generated backend auth secret was last rotated for
+	// +optional
+	BackendSecretRotation string `json:"backendSecretRotation,omitempty"`
+
+	// Schedule is the state of Spec.Schedule as evaluated on the last reconciliation
+	// +optional
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+}
+
+// ScheduleStatus is the state of the Backstage schedule
+type ScheduleStatus struct {
+	// Active is true if Backstage is scheduled to be running
+	Active bool `json:"active"`
+
+	// NextTransition is the time Backstage is started or stopped next by the schedule
+	NextTransition metav1.Time `json:"nextTransition"`
+}
+
+// ImageDigest is the digest the image is resolved to
+type ImageDigest struct {
+	// Image reference as configured
+	Image string `json:"image"`
+	// Digest of the image manifest
+	Digest string `json:"digest"`
+}
+
+// ExtraObjectRef identifies the object applied from RawRuntimeConfig ConfigMap
+type ExtraObjectRef struct {
+	// API version of the object
+	APIVersion string `json:"apiVersion"`
+	// Kind of the object
+	Kind string `json:"kind"`
+	// Name of the object
+	Name string `json:"name"`
 }
 
 //+kubebuilder:object:root=true
@@ -269,8 +519,26 @@ type TLS struct {
 
 	// caCertificate provides the cert authority certificate contents
 	CACertificate string `json:"caCertificate,omitempty"`
+
+	// Termination of the Route, one of:
+	// edge (default) - TLS is terminated by the router, the traffic to Backstage Pod is plain HTTP;
+	// reencrypt - TLS is terminated by the router and re-encrypted to Backstage Pod;
+	// passthrough - TLS is terminated by Backstage Pod, certificate, key and caCertificate must not be set.
+	// For reencrypt and passthrough, Backstage serves HTTPS with the certificate generated by OpenShift service CA
+	// (see service.beta.openshift.io/serving-cert-secret-name annotation).
+	// +optional
+	// +kubebuilder:validation:Enum=edge;reencrypt;passthrough
+	Termination TLSTermination `json:"termination,omitempty"`
 }
 
+type TLSTermination string
+
+const (
+	TLSTerminationEdge        TLSTermination = "edge"
+	TLSTerminationReencrypt   TLSTermination = "reencrypt"
+	TLSTerminationPassthrough TLSTermination = "passthrough"
+)
+
 func init() {
 	SchemeBuilder.Register(&Backstage{}, &BackstageList{})
 }
@@ -300,6 +568,31 @@ func (s *BackstageSpec) IsRouteEmpty() bool {
 	return false
 }
 
+// IsTrustedCASpecified returns true if any additional trusted CA is configured
+func (s *BackstageSpec) IsTrustedCASpecified() bool {
+	if s.Application == nil || s.Application.TrustedCA == nil {
+		return false
+	}
+	ca := s.Application.TrustedCA
+	return len(ca.ConfigMaps) > 0 || len(ca.Secrets) > 0 || ca.InjectClusterCABundle
+}
+
+// GetRouteTermination returns the termination of the Route, edge if not specified
+func (s *BackstageSpec) GetRouteTermination() TLSTermination {
+	if !s.IsRouteEnabled() || s.Application.Route.TLS == nil || s.Application.Route.TLS.Termination == "" {
+		return TLSTerminationEdge
+	}
+	return s.Application.Route.TLS.Termination
+}
+
 func (s *BackstageSpec) IsAuthSecretSpecified() bool {
 	return s.Database != nil && s.Database.AuthSecretName != ""
 }
+
+// GetDbDeletionPolicy returns the local database deletion policy, Retain if not specified
+func (s *BackstageSpec) GetDbDeletionPolicy() DbDeletionPolicy {
+	if s.Database == nil || s.Database.DeletionPolicy == "" {
+		return DbDeletionPolicyRetain
+	}
+	return s.Database.DeletionPolicy
+}
@@ -18,31 +18,53 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 
 	openshift "github.com/openshift/api/route/v1"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	corev1 "k8s.io/api/core/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
 
 	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
 
 	bs "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
 
+	configv1 "github.com/openshift/api/config/v1"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-var recNumber = 0
+// number of reconciliations, shared by concurrent reconcilers
+var recNumber atomic.Uint64
+
+// requeue interval of waiting for the objects of previous ApplyStage to be ready
+const readinessRequeueAfter = 5 * time.Second
+
+// AppliedHashAnnotation contains the hash of the runtime object rendered by the Operator,
+// the object is not patched if the hash of live one is the same
+const AppliedHashAnnotation = "rhdh.redhat.com/applied-hash"
 
 // BackstageReconciler reconciles a Backstage object
 type BackstageReconciler struct {
@@ -55,30 +77,70 @@ type BackstageReconciler struct {
 	// Namespace allows to restrict the reconciliation to this particular namespace,
 	// and ignore requests from other namespaces.
 	// This is mostly useful for our tests, to overcome a limitation of EnvTest about namespace deletion.
+	// To restrict the Operator to particular namespaces, use --watch-namespaces (env.WATCH_NAMESPACE) instead,
+	// which limits the manager cache
 	Namespace string
 
 	IsOpenShift bool
+
+	// WatchClusterProxy makes every Backstage CR reconciled on the change of OpenShift cluster Proxy.
+	// It requires cluster-wide permissions, so it is not enabled if particular namespaces are watched
+	WatchClusterProxy bool
+
+	// DefaultConfigMap refers to the ConfigMap containing Operator default configuration,
+	// profiles are stored in the ConfigMaps named <DefaultConfigMap.Name>-<profile>.
+	// Every Backstage CR is reconciled on their change.
+	// If not set, default configuration is read from the default-config directory
+	DefaultConfigMap types.NamespacedName
+
+	// RegistryMirrors rewrites container images and dynamic plugin packages to the mirrors,
+	// extended with registry-mirrors.yaml key of default configuration
+	RegistryMirrors model.RegistryMirrors
+
+	// ImageResolver resolves image digests if Spec.PinImageDigests is enabled,
+	// registry client (utils.RegistryResolver) is used if not set
+	ImageResolver utils.ImageResolver
+
+	// APIReader reads the objects directly from the API server, for example metadata of the Secrets
+	// which are not cached (nor readable through the Client). Client is used if not set
+	APIReader client.Reader
+
+	// MaxConcurrentReconciles is the maximum number of Backstage CRs reconciled concurrently, 1 if not set
+	MaxConcurrentReconciles int
+
+	// RetryBaseDelay and RetryMaxDelay define the exponential backoff of failed reconciliation retries,
+	// controller-runtime defaults (5ms and 1000s) are used if not set
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// IgnoreReadiness makes the runtime objects of all the stages (model.ApplyStage) applied at once,
+	// without waiting for readiness. This is mostly useful for EnvTest, which runs no controllers,
+	// so StatefulSet never becomes ready
+	IgnoreReadiness bool
 }
 
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=backstages,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=backstages/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=backstages/finalizers,verbs=update
-//+kubebuilder:rbac:groups="",resources=configmaps;services,verbs=get;watch;create;update;list;delete;patch
-//+kubebuilder:rbac:groups="",resources=persistentvolumes;persistentvolumeclaims,verbs=get;list;watch
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=create;delete;patch;update
+//+kubebuilder:rbac:groups="",resources=configmaps;serviceaccounts;services,verbs=get;watch;create;update;list;delete;patch
+//+kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;patch;update;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=create;delete;get;patch;update
 //+kubebuilder:rbac:groups="apps",resources=deployments,verbs=get;watch;create;update;list;delete;patch
 //+kubebuilder:rbac:groups="apps",resources=statefulsets,verbs=get;watch;create;update;list;delete;patch
+//+kubebuilder:rbac:groups="config.openshift.io",resources=proxies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="monitoring.coreos.com",resources=podmonitors;servicemonitors,verbs=get;create;update;delete;patch
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshots,verbs=get;create
 //+kubebuilder:rbac:groups="route.openshift.io",resources=routes;routes/custom-host,verbs=get;watch;create;update;list;delete;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.4/pkg/reconcile
-func (r *BackstageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *BackstageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	lg := log.FromContext(ctx)
 
-	recNumber = recNumber + 1
-	lg.V(1).Info(fmt.Sprintf("starting reconciliation (namespace: %q), number %d", req.NamespacedName, recNumber))
+	lg.V(1).Info(fmt.Sprintf("starting reconciliation (namespace: %q), number %d", req.NamespacedName, recNumber.Add(1)))
 
 	// Ignore requests for other namespaces, if specified.
 	// This is mostly useful for our tests, to overcome a limitation of EnvTest about namespace deletion.
@@ -91,13 +153,41 @@ func (r *BackstageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if err := r.Get(ctx, req.NamespacedName, &backstage); err != nil {
 		if errors.IsNotFound(err) {
 			lg.Info("backstage gone from the namespace")
+			deleteMetrics(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to load backstage deployment from the cluster: %w", err)
 	}
 
-	// This update will make sure the status is always updated in case of any errors or successful result
+	if !backstage.DeletionTimestamp.IsZero() {
+		done, err := r.finalize(ctx, &backstage)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to finalize backstage: %w", err)
+		}
+		if !done {
+			return ctrl.Result{RequeueAfter: readinessRequeueAfter}, nil
+		}
+		deleteMetrics(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		observeReconcile(req.NamespacedName, time.Since(start), err)
+	}()
+
+	if err := r.syncFinalizer(ctx, &backstage); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// This update will make sure the status is always updated in case of any errors or successful result,
+	// if it has changed, as the update triggers another reconciliation
+	origStatus := backstage.Status.DeepCopy()
 	defer func(bs *bs.Backstage) {
+		observeConditions(bs)
+		if equality.Semantic.DeepEqual(origStatus, &bs.Status) {
+			return
+		}
 		if err := r.Client.Status().Update(ctx, bs); err != nil {
 			if errors.IsConflict(err) {
 				lg.V(1).Info("Backstage object modified, retry syncing status", "Backstage Object", bs)
@@ -107,10 +197,26 @@ func (r *BackstageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}(&backstage)
 
+	// the runtime objects are left as is, until the annotation is removed
+	if model.IsReconcilePaused(backstage) {
+		lg.V(1).Info("reconciliation paused", "annotation", model.ReconcilePausedAnnotation)
+		setStatusCondition(&backstage, bs.BackstageConditionTypeDeployed, metav1.ConditionUnknown, bs.BackstageConditionReasonPaused,
+			fmt.Sprintf("reconciliation paused by %s annotation", model.ReconcilePausedAnnotation))
+		return ctrl.Result{}, nil
+	}
+
 	if len(backstage.Status.Conditions) == 0 {
 		setStatusCondition(&backstage, bs.BackstageConditionTypeDeployed, metav1.ConditionFalse, bs.BackstageConditionReasonInProgress, "Deployment process started")
 	}
 
+	// the state of the schedule is evaluated once per reconciliation and the model is built according to it
+	backstage.Status.Schedule = nil
+	if backstage.Spec.Schedule != nil {
+		if backstage.Status.Schedule, err = model.EvaluateSchedule(*backstage.Spec.Schedule, time.Now()); err != nil {
+			return ctrl.Result{}, errorAndStatus(&backstage, "failed to evaluate backstage schedule", err)
+		}
+	}
+
 	// 1. Preliminary read and prepare external config objects from the specs (configMaps, Secrets)
 	// 2. Make some validation to fail fast
 	externalConfig, err := r.preprocessSpec(ctx, backstage)
@@ -124,18 +230,58 @@ func (r *BackstageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, errorAndStatus(&backstage, "failed to initialize backstage model", err)
 	}
 
-	err = r.applyObjects(ctx, bsModel.RuntimeObjects)
+	if err := r.pinImageDigests(ctx, &backstage, bsModel); err != nil {
+		return ctrl.Result{}, errorAndStatus(&backstage, "failed to pin image digests", err)
+	}
+
+	if err := r.checkExtraObjects(bsModel.RuntimeObjects); err != nil {
+		return ctrl.Result{}, errorAndStatus(&backstage, "failed to apply backstage objects", err)
+	}
+
+	notReady, err := r.applyStages(ctx, bsModel)
 	if err != nil {
 		return ctrl.Result{}, errorAndStatus(&backstage, "failed to apply backstage objects", err)
 	}
+	// the backend auth secret is rotated (if requested)
+	backstage.Status.BackendSecretRotation = model.BackendSecretRotation(backstage)
+
+	if notReady != "" {
+		lg.V(1).Info("waiting for the object to be ready", "name", notReady)
+		setStatusCondition(&backstage, bs.BackstageConditionTypeDeployed, metav1.ConditionFalse, bs.BackstageConditionReasonInProgress,
+			fmt.Sprintf("waiting for %s to be ready", notReady))
+		return ctrl.Result{RequeueAfter: readinessRequeueAfter}, nil
+	}
+
+	plugins, err := bsModel.EnabledDynamicPlugins()
+	if err != nil {
+		lg.Error(err, "failed to count enabled dynamic plugins")
+	}
+	observeApply(&backstage, len(bsModel.RuntimeObjects), plugins)
 
 	if err := r.cleanObjects(ctx, backstage); err != nil {
 		return ctrl.Result{}, errorAndStatus(&backstage, "failed to clean backstage objects ", err)
 	}
 
-	setStatusCondition(&backstage, bs.BackstageConditionTypeDeployed, metav1.ConditionTrue, bs.BackstageConditionReasonDeployed, "")
+	if err := r.pruneExtraObjects(ctx, &backstage, bsModel.RuntimeObjects); err != nil {
+		return ctrl.Result{}, errorAndStatus(&backstage, "failed to clean backstage objects ", err)
+	}
 
-	return ctrl.Result{}, nil
+	if model.IsSuspended(backstage) {
+		setStatusCondition(&backstage, bs.BackstageConditionTypeDeployed, metav1.ConditionTrue, bs.BackstageConditionReasonSuspended, "scaled down to zero replicas")
+	} else {
+		setStatusCondition(&backstage, bs.BackstageConditionTypeDeployed, metav1.ConditionTrue, bs.BackstageConditionReasonDeployed, "")
+	}
+
+	return ctrl.Result{RequeueAfter: scheduleRequeueAfter(backstage)}, nil
+}
+
+// scheduleRequeueAfter returns the duration until the next transition of the schedule, zero if there is no schedule
+func scheduleRequeueAfter(backstage bs.Backstage) time.Duration {
+	if backstage.Status.Schedule == nil {
+		return 0
+	}
+	// not less than a second, in case the transition time has just passed
+	return max(time.Until(backstage.Status.Schedule.NextTransition.Time), time.Second)
 }
 
 func errorAndStatus(backstage *bs.Backstage, msg string, err error) error {
@@ -143,15 +289,52 @@ func errorAndStatus(backstage *bs.Backstage, msg string, err error) error {
 	return fmt.Errorf("%s %w", msg, err)
 }
 
+// applyStages applies the runtime objects stage by stage (model.ApplyStage), the next stage is applied
+// only when the objects of previous one are ready (model.ReadinessGate).
+// Returns the name of the object which is not ready yet, if any
+func (r *BackstageReconciler) applyStages(ctx context.Context, bsModel *model.BackstageModel) (string, error) {
+	for _, stage := range bsModel.ApplyStages() {
+		if err := r.applyObjects(ctx, stage); err != nil {
+			return "", err
+		}
+		if r.IgnoreReadiness {
+			continue
+		}
+		for _, obj := range stage {
+			gate, ok := obj.(model.ReadinessGate)
+			if !ok {
+				continue
+			}
+			live := obj.EmptyObject()
+			if err := r.Get(ctx, client.ObjectKeyFromObject(obj.Object()), live); err != nil {
+				// just created, may be not in the cache yet
+				if errors.IsNotFound(err) {
+					return obj.Object().GetName(), nil
+				}
+				return "", fmt.Errorf("failed to get object %s: %w", obj.Object().GetName(), err)
+			}
+			if !gate.IsReady(live) {
+				return obj.Object().GetName(), nil
+			}
+		}
+	}
+	return "", nil
+}
+
 func (r *BackstageReconciler) applyObjects(ctx context.Context, objects []model.RuntimeObject) error {
 
 	lg := log.FromContext(ctx)
 
 	for _, obj := range objects {
 
+		hash, err := setAppliedHash(obj.Object())
+		if err != nil {
+			return fmt.Errorf("failed to hash object %s: %w", obj.Object().GetName(), err)
+		}
+
 		baseObject := obj.EmptyObject()
 		// do not read Secrets
-		if _, ok := obj.Object().(*corev1.Secret); ok {
+		if isSecret(obj.Object()) {
 			// try to create
 			if err := r.Create(ctx, obj.Object()); err != nil {
 				if !errors.IsAlreadyExists(err) {
@@ -161,6 +344,21 @@ func (r *BackstageReconciler) applyObjects(ctx context.Context, objects []model.
 				if _, ok := obj.(*model.DbSecret); ok {
 					continue
 				}
+				// generated backend auth secret is updated on rotation request only, the rotation handled last
+				// is recorded in the Secret, so the Secret is not regenerated if the status update is lost
+				if bas, ok := obj.(*model.BackendAuthSecret); ok {
+					if bas.Rotation() == "" {
+						continue
+					}
+					live := &metav1.PartialObjectMetadata{}
+					live.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+					if err := r.apiReader().Get(ctx, client.ObjectKeyFromObject(obj.Object()), live); err != nil {
+						return fmt.Errorf("failed to get backend auth secret: %w", err)
+					}
+					if !bas.IsRotationRequested(live) {
+						continue
+					}
+				}
 			} else {
 				lg.V(1).Info("create secret ", objDispName(obj), obj.Object().GetName())
 				continue
@@ -179,6 +377,21 @@ func (r *BackstageReconciler) applyObjects(ctx context.Context, objects []model.
 				lg.V(1).Info("create object ", objDispName(obj), obj.Object().GetName())
 				continue
 			}
+			// the content is injected by OpenShift, nothing to update
+			if _, ok := obj.(*model.TrustedCABundle); ok {
+				continue
+			}
+			// PVCs are not part of the StatefulSet, so they are checked even if it is unchanged
+			if sts, ok := obj.(*model.DbStatefulSet); ok {
+				if err := r.expandDbVolumes(ctx, baseObject.(*appsv1.StatefulSet), sts.Object().(*appsv1.StatefulSet)); err != nil {
+					return err
+				}
+			}
+			// rendered the same way as the last time
+			if baseObject.GetAnnotations()[AppliedHashAnnotation] == hash {
+				lg.V(1).Info("skip unchanged object ", objDispName(obj), obj.Object().GetName())
+				continue
+			}
 		}
 
 		if err := r.patchObject(ctx, baseObject, obj); err != nil {
@@ -191,6 +404,32 @@ func (r *BackstageReconciler) applyObjects(ctx context.Context, objects []model.
 	return nil
 }
 
+// setAppliedHash annotates the rendered object with its hash (AppliedHashAnnotation)
+func setAppliedHash(obj client.Object) (string, error) {
+	hash, err := utils.HashObject(obj)
+	if err != nil {
+		return "", err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AppliedHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+	return hash, nil
+}
+
+// isSecret returns true for both typed and unstructured (extra object) Secrets
+func isSecret(obj client.Object) bool {
+	if _, ok := obj.(*corev1.Secret); ok {
+		return true
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.GroupVersionKind().GroupKind() == corev1.SchemeGroupVersion.WithKind("Secret").GroupKind()
+	}
+	return false
+}
+
 func objDispName(obj model.RuntimeObject) string {
 	return reflect.TypeOf(obj.Object()).String()
 }
@@ -255,6 +494,20 @@ func (r *BackstageReconciler) cleanObjects(ctx context.Context, backstage bs.Bac
 		}
 	}
 
+	// check if Backstage backend HTTPS disabled
+	if r.IsOpenShift && !model.IsBackendTLSEnabled(backstage.Spec, r.IsOpenShift) {
+		if err := r.tryToDelete(ctx, &corev1.ConfigMap{}, model.HTTPSAppConfigName(backstage.Name), backstage.Namespace); err != nil {
+			return fmt.Errorf("%s %w", failedToCleanup, err)
+		}
+	}
+
+	// check if cluster CA bundle injection disabled
+	if r.IsOpenShift && (!backstage.Spec.IsTrustedCASpecified() || !backstage.Spec.Application.TrustedCA.InjectClusterCABundle) {
+		if err := r.tryToDelete(ctx, &corev1.ConfigMap{}, model.TrustedCABundleName(backstage.Name), backstage.Namespace); err != nil {
+			return fmt.Errorf("%s %w", failedToCleanup, err)
+		}
+	}
+
 	return nil
 }
 
@@ -282,7 +535,11 @@ func setStatusCondition(backstage *bs.Backstage, condType bs.BackstageConditionT
 func (r *BackstageReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	builder := ctrl.NewControllerManagedBy(mgr).
-		For(&bs.Backstage{})
+		For(&bs.Backstage{}).
+		WithOptions(ctrlcontroller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.rateLimiter(),
+		})
 
 	// [GA] do not remove it
 	//if r.OwnsRuntime {
@@ -291,5 +548,58 @@ func (r *BackstageReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	//		Owns(&appsv1.StatefulSet{})
 	//}
 
+	if r.DefaultConfigMap.Name != "" {
+		builder.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.requestsForAllBackstages),
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				// the default ConfigMap or any of the profiles
+				return obj.GetNamespace() == r.DefaultConfigMap.Namespace &&
+					(obj.GetName() == r.DefaultConfigMap.Name || strings.HasPrefix(obj.GetName(), r.DefaultConfigMap.Name+"-"))
+			})))
+	}
+
+	if r.IsOpenShift && r.WatchClusterProxy {
+		builder.Watches(&configv1.Proxy{}, handler.EnqueueRequestsFromMapFunc(r.requestsForAllBackstages),
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == clusterProxyName
+			})))
+	}
+
 	return builder.Complete(r)
 }
+
+func (r *BackstageReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// rateLimiter is the controller-runtime default one with configurable per item exponential backoff
+func (r *BackstageReconciler) rateLimiter() workqueue.RateLimiter {
+	baseDelay, maxDelay := 5*time.Millisecond, 1000*time.Second
+	if r.RetryBaseDelay > 0 {
+		baseDelay = r.RetryBaseDelay
+	}
+	if r.RetryMaxDelay > 0 {
+		maxDelay = r.RetryMaxDelay
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		// overall retry rate: 10 qps, 100 bucket size
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// requestsForAllBackstages makes reconcile requests for every Backstage CR
+func (r *BackstageReconciler) requestsForAllBackstages(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := &bs.BackstageList{}
+	if err := r.List(ctx, list); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Backstage objects")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: item.Name, Namespace: item.Namespace}})
+	}
+	return requests
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
 	"strings"
 	"time"
 
@@ -25,13 +26,16 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
@@ -72,6 +76,8 @@ var _ = Describe("Backstage controller", func() {
 			Scheme:      k8sClient.Scheme(),
 			Namespace:   ns,
 			OwnsRuntime: true,
+			// no StatefulSet controller in EnvTest
+			IgnoreReadiness: true,
 			//PsqlImage:      "test-postgresql-15:latest",
 			//BackstageImage: "test-backstage-showcase:next",
 		}
@@ -176,9 +182,16 @@ var _ = Describe("Backstage controller", func() {
 		return list[0], true
 	}
 
+	// volumes of ConfigMaps and Secrets are named with utils.GenerateVolumeNameFromCmOrSecret
+	isVolumeOf := func(volumeName string, name string) bool {
+		return volumeName == name ||
+			volumeName == utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, name) ||
+			volumeName == utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, name)
+	}
+
 	findVolume := func(vols []corev1.Volume, name string) (corev1.Volume, bool) {
 		list := findElementsByPredicate(vols, func(vol corev1.Volume) bool {
-			return vol.Name == name
+			return isVolumeOf(vol.Name, name)
 		})
 		if len(list) == 0 {
 			return corev1.Volume{}, false
@@ -188,7 +201,7 @@ var _ = Describe("Backstage controller", func() {
 
 	findVolumeMounts := func(mounts []corev1.VolumeMount, name string) []corev1.VolumeMount {
 		return findElementsByPredicate(mounts, func(mount corev1.VolumeMount) bool {
-			return mount.Name == name
+			return isVolumeOf(mount.Name, name)
 		})
 	}
 
@@ -253,6 +266,13 @@ var _ = Describe("Backstage controller", func() {
 			})
 			Expect(err).To(Not(HaveOccurred()))
 
+			By("exposing the metrics of the Backstage instance")
+			Expect(testutil.ToFloat64(reconcileErrors.WithLabelValues(ns, backstageName))).To(BeZero())
+			Expect(testutil.ToFloat64(managedObjects.WithLabelValues(ns, backstageName))).To(BeNumerically(">", 0))
+			Expect(testutil.ToFloat64(lastSuccessfulApply.WithLabelValues(ns, backstageName))).To(BeNumerically(">", 0))
+			Expect(testutil.ToFloat64(conditionStatus.WithLabelValues(ns, backstageName,
+				string(bsv1alpha1.BackstageConditionTypeDeployed), string(metav1.ConditionTrue)))).To(Equal(1.0))
+
 			By("creating a secret for accessing the Database")
 			Eventually(func(g Gomega) {
 				found := &corev1.Secret{}
@@ -271,14 +291,22 @@ var _ = Describe("Backstage controller", func() {
 				g.Expect(secName).Should(Equal(model.DbSecretDefaultName(backstage.Name)))
 			}, time.Minute, time.Second).Should(Succeed())
 
-			//backendAuthConfigName := fmt.Sprintf("%s-auth-app-config", backstageName)
-			backendAuthConfigName := model.AppConfigDefaultName(backstageName)
+			backendAuthConfigName := model.BackendAuthAppConfigName(backstageName)
 			By("Creating a ConfigMap for default backend auth key", func() {
 				Eventually(func(g Gomega) {
 					found := &corev1.ConfigMap{}
 					err := k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: backendAuthConfigName}, found)
 					g.Expect(err).ShouldNot(HaveOccurred())
-					g.Expect(found.Data).ToNot(BeEmpty(), "backend auth secret should contain non-empty data")
+					g.Expect(found.Data).ToNot(BeEmpty(), "backend auth config should contain non-empty data")
+				}, time.Minute, time.Second).Should(Succeed())
+			})
+
+			By("Generating a Secret for backend auth key", func() {
+				Eventually(func(g Gomega) {
+					found := &corev1.Secret{}
+					err := k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.BackendAuthSecretName(backstageName)}, found)
+					g.Expect(err).ShouldNot(HaveOccurred())
+					g.Expect(found.Data).To(HaveKey("BACKEND_SECRET"))
 				}, time.Minute, time.Second).Should(Succeed())
 			})
 
@@ -306,7 +334,7 @@ var _ = Describe("Backstage controller", func() {
 			Expect(found.Spec.Replicas).To(HaveValue(BeEquivalentTo(1)))
 
 			By("Checking the Volumes in the Backstage Deployment", func() {
-				Expect(found.Spec.Template.Spec.Volumes).To(HaveLen(4))
+				Expect(found.Spec.Template.Spec.Volumes).To(HaveLen(5))
 
 				dpRootVol, ok := findVolume(found.Spec.Template.Spec.Volumes, "dynamic-plugins-root")
 				Expect(ok).To(BeTrue(), "No volume found with name: dynamic-plugins-root")
@@ -375,15 +403,17 @@ var _ = Describe("Backstage controller", func() {
 			mainCont := found.Spec.Template.Spec.Containers[0]
 
 			By("Checking the main container Args in the Backstage Deployment", func() {
-				Expect(mainCont.Args).To(HaveLen(4))
+				Expect(mainCont.Args).To(HaveLen(6))
 				Expect(mainCont.Args[0]).To(Equal("--config"))
 				Expect(mainCont.Args[1]).To(Equal("dynamic-plugins-root/app-config.dynamic-plugins.yaml"))
 				Expect(mainCont.Args[2]).To(Equal("--config"))
 				Expect(mainCont.Args[3]).To(Equal("/opt/app-root/src/default.app-config.yaml"))
+				Expect(mainCont.Args[4]).To(Equal("--config"))
+				Expect(mainCont.Args[5]).To(Equal("/opt/app-root/src/app-config.backend-auth.yaml"))
 			})
 
 			By("Checking the main container Volume Mounts in the Backstage Deployment", func() {
-				Expect(mainCont.VolumeMounts).To(HaveLen(2))
+				Expect(mainCont.VolumeMounts).To(HaveLen(3))
 
 				dpRoot := findVolumeMounts(mainCont.VolumeMounts, "dynamic-plugins-root")
 				Expect(dpRoot).To(HaveLen(1), "No volume mount found with name: dynamic-plugins-root")
@@ -392,8 +422,8 @@ var _ = Describe("Backstage controller", func() {
 
 				bsAuth := findVolumeMounts(mainCont.VolumeMounts, backendAuthConfigName)
 				Expect(bsAuth).To(HaveLen(1), "No volume mount found with name: %s", backendAuthConfigName)
-				Expect(bsAuth[0].MountPath).To(Equal("/opt/app-root/src/default.app-config.yaml"))
-				Expect(bsAuth[0].SubPath).To(Equal("default.app-config.yaml"))
+				Expect(bsAuth[0].MountPath).To(Equal("/opt/app-root/src/app-config.backend-auth.yaml"))
+				Expect(bsAuth[0].SubPath).To(Equal("app-config.backend-auth.yaml"))
 			})
 
 			By("Checking the db secret used by the Backstage Deployment")
@@ -741,8 +771,9 @@ spec:
 							//test-backstage-cqzfx-default-appconfig
 							//my-app-config-1-cm
 							//my-dynamic-plugins-config
+							//test-backstage-cqzfx-backstage-backend-auth-appconfig
 							By("Checking the Volumes in the Backstage Deployment", func() {
-								Expect(found.Spec.Template.Spec.Volumes).To(HaveLen(5))
+								Expect(found.Spec.Template.Spec.Volumes).To(HaveLen(6))
 
 								_, ok := findVolume(found.Spec.Template.Spec.Volumes, "dynamic-plugins-root")
 								Expect(ok).To(BeTrue(), "No volume found with name: dynamic-plugins-root")
@@ -812,18 +843,22 @@ spec:
 								//          "--config",
 								//          "/opt/app-root/src/default.app-config.yaml",
 								//          "--config",
+								//          "/opt/app-root/src/app-config.backend-auth.yaml",
+								//          "--config",
 								//          "/some/path/for/app-config/my-app-config-11.yaml",
 								//          "--config",
 								//          "/some/path/for/app-config/my-app-config-12.yaml",
-								nbArgs := 8
+								nbArgs := 10
 								if key != "" {
 									//          "--config",
 									//          "dynamic-plugins-root/app-config.dynamic-plugins.yaml",
 									//          "--config",
 									//          "/opt/app-root/src/default.app-config.yaml",
 									//          "--config",
+									//          "/opt/app-root/src/app-config.backend-auth.yaml",
+									//          "--config",
 									//          "/some/path/for/app-config/my-app-config-12.yaml",
-									nbArgs = 6
+									nbArgs = 8
 								}
 								Expect(mainCont.Args).To(HaveLen(nbArgs))
 								Expect(mainCont.Args[1]).To(Equal("dynamic-plugins-root/app-config.dynamic-plugins.yaml"))
@@ -834,32 +869,34 @@ spec:
 									//TODO(rm3l): the order of the rest of the --config args should be the same as the order in
 									// which the keys are listed in the ConfigMap/Secrets
 									// But as this is returned as a map, Go does not provide any guarantee on the iteration order.
-									Expect(mainCont.Args[5]).To(SatisfyAny(
+									Expect(mainCont.Args[7]).To(SatisfyAny(
 										Equal(expectedMountPath+"/my-app-config-11.yaml"),
 										Equal(expectedMountPath+"/my-app-config-12.yaml"),
 									))
-									Expect(mainCont.Args[7]).To(SatisfyAny(
+									Expect(mainCont.Args[9]).To(SatisfyAny(
 										Equal(expectedMountPath+"/my-app-config-11.yaml"),
 										Equal(expectedMountPath+"/my-app-config-12.yaml"),
 									))
-									Expect(mainCont.Args[3]).To(Not(Equal(mainCont.Args[5])))
+									Expect(mainCont.Args[7]).To(Not(Equal(mainCont.Args[9])))
 								} else {
-									Expect(mainCont.Args[5]).To(Equal(fmt.Sprintf("%s/%s", expectedMountPath, key)))
+									Expect(mainCont.Args[7]).To(Equal(fmt.Sprintf("%s/%s", expectedMountPath, key)))
 								}
 							})
 
 							By("Checking the main container Volume Mounts in the Backstage Deployment", func() {
 								//"/opt/app-root/src/dynamic-plugins-root"
 								//"/opt/app-root/src/default.app-config.yaml"
+								//"/opt/app-root/src/app-config.backend-auth.yaml"
 								// /some/path/for/app-config/my-app-config-11.yaml"
 								//"/some/path/for/app-config/my-app-config-12.yaml"
-								nbMounts := 4
+								nbMounts := 5
 								nbMounts2 := 2
 								if key != "" {
 									//"/opt/app-root/src/dynamic-plugins-root"
 									//"/opt/app-root/src/default.app-config.yaml"
+									//"/opt/app-root/src/app-config.backend-auth.yaml"
 									//"/some/path/for/app-config/my-app-config-11.yaml"
-									nbMounts = 3
+									nbMounts = 4
 									nbMounts2 = 1
 								}
 								Expect(mainCont.VolumeMounts).To(HaveLen(nbMounts))
@@ -1058,7 +1095,7 @@ spec:
 					})
 
 					By("Checking the Volumes in the Backstage Deployment", func() {
-						Expect(found.Spec.Template.Spec.Volumes).To(HaveLen(7))
+						Expect(found.Spec.Template.Spec.Volumes).To(HaveLen(8))
 
 						backendAuthAppConfigVol, ok := findVolume(found.Spec.Template.Spec.Volumes, backendAuthConfigName)
 						Expect(ok).To(BeTrue(), "No volume found with name: %s", backendAuthConfigName)
@@ -1096,7 +1133,7 @@ spec:
 					mainCont := found.Spec.Template.Spec.Containers[0]
 
 					By("Checking the main container Volume Mounts in the Backstage Deployment", func() {
-						Expect(mainCont.VolumeMounts).To(HaveLen(6))
+						Expect(mainCont.VolumeMounts).To(HaveLen(7))
 
 						expectedMountPath := mountPath
 						if expectedMountPath == "" {
@@ -1541,6 +1578,297 @@ spec:
 			Expect(err).Should(Not(HaveOccurred()))
 		})
 	})
+
+	Context("Backend auth secret rotation", func() {
+		It("should rotate the secret once per requested rotation", func() {
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{})
+			backstage.SetAnnotations(map[string]string{model.BackendSecretRotationAnnotation: "1"})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			By("Reconciling the custom resource created")
+			_, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.BackendAuthSecretName(backstageName)}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey("BACKEND_SECRET"))
+			value := secret.Data["BACKEND_SECRET"]
+
+			By("Losing the status update and reconciling again")
+			found := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, found)).To(Succeed())
+			found.Status.BackendSecretRotation = ""
+			Expect(k8sClient.Status().Update(ctx, found)).To(Succeed())
+			_, err = backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.BackendAuthSecretName(backstageName)}, secret)).To(Succeed())
+			Expect(secret.Data["BACKEND_SECRET"]).To(Equal(value))
+
+			By("Requesting another rotation")
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, found)).To(Succeed())
+			found.SetAnnotations(map[string]string{model.BackendSecretRotationAnnotation: "2"})
+			Expect(k8sClient.Update(ctx, found)).To(Succeed())
+			_, err = backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.BackendAuthSecretName(backstageName)}, secret)).To(Succeed())
+			Expect(secret.Data["BACKEND_SECRET"]).ToNot(Equal(value))
+		})
+	})
+
+	Context("Unchanged objects", func() {
+		It("should not patch the objects rendered the same way", func() {
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{
+				Database: &bsv1alpha1.Database{
+					EnableLocalDb: ptr.To(false),
+				},
+			})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			reconcileAndGet := func() *appsv1.Deployment {
+				_, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+				})
+				Expect(err).To(Not(HaveOccurred()))
+				found := &appsv1.Deployment{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, found)).To(Succeed())
+				return found
+			}
+
+			By("Reconciling the custom resource created")
+			deployment := reconcileAndGet()
+			Expect(deployment.Annotations).To(HaveKey(AppliedHashAnnotation))
+
+			By("Reconciling the unchanged custom resource")
+			Expect(reconcileAndGet().ResourceVersion).To(Equal(deployment.ResourceVersion))
+
+			By("Reconciling the changed custom resource")
+			Eventually(func(g Gomega) {
+				toBeUpdated := &bsv1alpha1.Backstage{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, toBeUpdated)).To(Succeed())
+				toBeUpdated.Spec.Application = &bsv1alpha1.Application{Replicas: ptr.To(int32(2))}
+				g.Expect(k8sClient.Update(ctx, toBeUpdated)).To(Succeed())
+			}, time.Minute, time.Second).Should(Succeed())
+			updated := reconcileAndGet()
+			Expect(updated.ResourceVersion).To(Not(Equal(deployment.ResourceVersion)))
+			Expect(updated.Annotations[AppliedHashAnnotation]).To(Not(Equal(deployment.Annotations[AppliedHashAnnotation])))
+		})
+	})
+
+	Context("Readiness", func() {
+		It("should apply Backstage Deployment when the local database is ready", func() {
+			backstageReconciler.IgnoreReadiness = false
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			By("Reconciling the custom resource created")
+			result, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the local database is applied, but Backstage Deployment is not")
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DbStatefulSetName(backstageName)}, sts)).To(Succeed())
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, &appsv1.Deployment{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), fmtNotFound, err)
+
+			found := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, found)).To(Succeed())
+			cond := meta.FindStatusCondition(found.Status.Conditions, string(bsv1alpha1.BackstageConditionTypeDeployed))
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.Reason).To(Equal(string(bsv1alpha1.BackstageConditionReasonInProgress)))
+
+			By("Making the local database ready")
+			sts.Status.Replicas = 1
+			sts.Status.ReadyReplicas = 1
+			sts.Status.ObservedGeneration = sts.Generation
+			Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+
+			By("Reconciling again")
+			Eventually(func(g Gomega) {
+				result, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+				})
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(result.RequeueAfter).To(BeZero())
+			}, time.Minute, time.Second).Should(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, &appsv1.Deployment{})).To(Succeed())
+			verifyBackstageInstance(ctx)
+		})
+	})
+
+	Context("Suspend and pause", func() {
+		It("should scale Backstage and local database down to zero replicas when suspended", func() {
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{Suspend: true})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			By("Reconciling the custom resource created")
+			_, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Checking the replicas")
+			deploy := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, deploy)).To(Succeed())
+			Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(0))
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DbStatefulSetName(backstageName)}, sts)).To(Succeed())
+			Expect(*sts.Spec.Replicas).To(BeEquivalentTo(0))
+
+			found := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, found)).To(Succeed())
+			cond := meta.FindStatusCondition(found.Status.Conditions, string(bsv1alpha1.BackstageConditionTypeDeployed))
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.Reason).To(Equal(string(bsv1alpha1.BackstageConditionReasonSuspended)))
+		})
+
+		It("should expand local database volumes when suspended", func() {
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{
+				Suspend: true,
+				Database: &bsv1alpha1.Database{
+					Storage: &bsv1alpha1.DbStorage{Size: ptr.To(resource.MustParse("2Gi"))},
+				},
+			})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			By("Reconciling the custom resource created")
+			_, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Creating the smaller PersistentVolumeClaim of the scaled down StatefulSet")
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("data-%s-0", model.DbStatefulSetName(backstageName)), Namespace: ns},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			// only bound claims can be expanded
+			pvc.Status.Phase = corev1.ClaimBound
+			Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+
+			By("Reconciling the unchanged custom resource")
+			_, err = backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("2Gi"))
+			}, time.Minute, time.Second).Should(Succeed())
+		})
+
+		It("should not touch runtime objects when reconciliation is paused", func() {
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{})
+			backstage.SetAnnotations(map[string]string{model.ReconcilePausedAnnotation: "true"})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			By("Reconciling the custom resource created")
+			_, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Checking no Backstage Deployment is created")
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, &appsv1.Deployment{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), fmtNotFound, err)
+
+			found := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, found)).To(Succeed())
+			cond := meta.FindStatusCondition(found.Status.Conditions, string(bsv1alpha1.BackstageConditionTypeDeployed))
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.Reason).To(Equal(string(bsv1alpha1.BackstageConditionReasonPaused)))
+		})
+
+		It("should suspend Backstage outside of the scheduled window and requeue at the next transition", func() {
+			// stopped until the next minute starts it
+			backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{
+				Schedule: &bsv1alpha1.Schedule{Start: "* * * * *", Stop: "0 0 1 1 *"},
+			})
+			Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+
+			By("Reconciling the custom resource created")
+			result, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+
+			By("Checking the replicas and the schedule status")
+			deploy := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, deploy)).To(Succeed())
+			Expect(*deploy.Spec.Replicas).To(BeEquivalentTo(0))
+
+			found := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, found)).To(Succeed())
+			Expect(found.Status.Schedule).ToNot(BeNil())
+			Expect(found.Status.Schedule.Active).To(BeFalse())
+			Expect(found.Status.Schedule.NextTransition.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+	})
+
+	Context("Concurrency", func() {
+		It("should reconcile several custom resources concurrently", func() {
+			names := []string{backstageName + "-1", backstageName + "-2", backstageName + "-3"}
+			for _, name := range names {
+				backstage := buildBackstageCR(bsv1alpha1.BackstageSpec{
+					Database: &bsv1alpha1.Database{
+						EnableLocalDb: ptr.To(false),
+					},
+				})
+				backstage.Name = name
+				Expect(k8sClient.Create(ctx, backstage)).To(Succeed())
+			}
+
+			By("Reconciling the custom resources concurrently")
+			errs := make(chan error, len(names))
+			for _, name := range names {
+				go func(name string) {
+					defer GinkgoRecover()
+					_, err := backstageReconciler.Reconcile(ctx, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: name, Namespace: ns},
+					})
+					errs <- err
+				}(name)
+			}
+			for range names {
+				Expect(<-errs).To(Not(HaveOccurred()))
+			}
+
+			for _, name := range names {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(name)}, &appsv1.Deployment{})).To(Succeed())
+			}
+		})
+
+		It("should back off failed reconciliations with configured delays", func() {
+			backstageReconciler.RetryBaseDelay = time.Second
+			backstageReconciler.RetryMaxDelay = 3 * time.Second
+			limiter := backstageReconciler.rateLimiter()
+
+			Expect(limiter.When("bs")).To(Equal(time.Second))
+			Expect(limiter.When("bs")).To(Equal(2 * time.Second))
+			Expect(limiter.When("bs")).To(Equal(3 * time.Second))
+			// per item
+			Expect(limiter.When("other")).To(Equal(time.Second))
+		})
+	})
 })
 
 func findElementsByPredicate[T any](l []T, predicate func(t T) bool) (result []T) {
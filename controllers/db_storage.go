@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// expandDbVolumes keeps the volumeClaimTemplates of the live local DB StatefulSet, as they are immutable,
+// and instead expands the PersistentVolumeClaims created from them if the desired size is bigger.
+// The PersistentVolumeClaims are found by name, so they are expanded even if the StatefulSet is scaled down
+func (r *BackstageReconciler) expandDbVolumes(ctx context.Context, live *appsv1.StatefulSet, desired *appsv1.StatefulSet) error {
+
+	lg := log.FromContext(ctx)
+
+	var pvcs *corev1.PersistentVolumeClaimList
+	for _, tmpl := range desired.Spec.VolumeClaimTemplates {
+		size, ok := tmpl.Spec.Resources.Requests[corev1.ResourceStorage]
+		if !ok {
+			continue
+		}
+		if pvcs == nil {
+			pvcs = &corev1.PersistentVolumeClaimList{}
+			if err := r.List(ctx, pvcs, client.InNamespace(live.Namespace)); err != nil {
+				return fmt.Errorf("failed to list PersistentVolumeClaims: %w", err)
+			}
+		}
+		for i := range pvcs.Items {
+			pvc := &pvcs.Items[i]
+			if !isStatefulSetClaim(pvc.Name, tmpl.Name, live.Name) {
+				continue
+			}
+
+			current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if size.Cmp(current) <= 0 {
+				continue
+			}
+
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			if err := r.Patch(ctx, pvc, patch); err != nil {
+				return fmt.Errorf("failed to expand PersistentVolumeClaim %s: %w", pvc.Name, err)
+			}
+			lg.V(1).Info("expand PersistentVolumeClaim ", pvc.Name, size.String())
+		}
+	}
+
+	desired.Spec.VolumeClaimTemplates = live.Spec.VolumeClaimTemplates
+
+	return nil
+}
+
+// isStatefulSetClaim returns true if the PersistentVolumeClaim is created from the template of the StatefulSet,
+// such claims are named <template>-<statefulset>-<ordinal>
+func isStatefulSetClaim(pvcName string, template string, statefulSet string) bool {
+	ordinal, ok := strings.CutPrefix(pvcName, fmt.Sprintf("%s-%s-", template, statefulSet))
+	if !ok {
+		return false
+	}
+	_, err := strconv.ParseUint(ordinal, 10, 32)
+	return err == nil
+}
@@ -0,0 +1,89 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	bs "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+)
+
+// checkExtraObjects makes sure all the extra objects are namespaced, as they are created in the namespace of Backstage CR
+func (r *BackstageReconciler) checkExtraObjects(objects []model.RuntimeObject) error {
+	for _, obj := range objects {
+		extra, ok := obj.(*model.ExtraObject)
+		if !ok {
+			continue
+		}
+		namespaced, err := r.IsObjectNamespaced(extra.Object())
+		if err != nil {
+			return fmt.Errorf("failed to check the scope of the object defined in %s: %w", extra.Key(), err)
+		}
+		if !namespaced {
+			return fmt.Errorf("object defined in %s is cluster scoped, only namespaced objects are allowed", extra.Key())
+		}
+	}
+	return nil
+}
+
+// pruneExtraObjects deletes the extra objects applied before (as recorded in the status)
+// and not defined in the configuration anymore, then records the current ones in the status
+func (r *BackstageReconciler) pruneExtraObjects(ctx context.Context, backstage *bs.Backstage, objects []model.RuntimeObject) error {
+
+	lg := log.FromContext(ctx)
+
+	current := make([]bs.ExtraObjectRef, 0)
+	for _, obj := range objects {
+		if extra, ok := obj.(*model.ExtraObject); ok {
+			gvk := extra.Object().GetObjectKind().GroupVersionKind()
+			current = append(current, bs.ExtraObjectRef{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind, Name: extra.Object().GetName()})
+		}
+	}
+
+	for _, ref := range backstage.Status.ExtraObjects {
+		if containsExtraObjectRef(current, ref) {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+		if err := r.tryToDelete(ctx, obj, ref.Name, backstage.Namespace); err != nil {
+			return err
+		}
+		lg.V(1).Info("delete extra object ", ref.Kind, ref.Name)
+	}
+
+	backstage.Status.ExtraObjects = nil
+	if len(current) > 0 {
+		backstage.Status.ExtraObjects = current
+	}
+	return nil
+}
+
+// the same object, regardless of API version
+func containsExtraObjectRef(refs []bs.ExtraObjectRef, ref bs.ExtraObjectRef) bool {
+	group := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).Group
+	for _, r := range refs {
+		if r.Kind == ref.Kind && r.Name == ref.Name && schema.FromAPIVersionAndKind(r.APIVersion, r.Kind).Group == group {
+			return true
+		}
+	}
+	return false
+}
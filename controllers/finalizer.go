@@ -0,0 +1,194 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	bs "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+)
+
+// DbFinalizer makes the Backstage CR wait for the local database clean up
+// according to spec.database.deletionPolicy before being deleted
+const DbFinalizer = "rhdh.redhat.com/local-db"
+
+var volumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// syncFinalizer adds the finalizer if local database is enabled and removes it otherwise
+func (r *BackstageReconciler) syncFinalizer(ctx context.Context, backstage *bs.Backstage) error {
+	var updated bool
+	if backstage.Spec.IsLocalDbEnabled() {
+		updated = controllerutil.AddFinalizer(backstage, DbFinalizer)
+	} else {
+		updated = controllerutil.RemoveFinalizer(backstage, DbFinalizer)
+	}
+	if updated {
+		if err := r.Update(ctx, backstage); err != nil {
+			return fmt.Errorf("failed to update finalizers: %w", err)
+		}
+	}
+	return nil
+}
+
+// finalize applies the local database deletion policy and removes the finalizer.
+// Returns false if the finalization is not complete yet (the snapshots are not ready) and has to be retried
+func (r *BackstageReconciler) finalize(ctx context.Context, backstage *bs.Backstage) (bool, error) {
+
+	lg := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(backstage, DbFinalizer) {
+		return true, nil
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcs, client.InNamespace(backstage.Namespace), client.MatchingLabels(model.DbSelectorLabels(backstage.Name))); err != nil {
+		return false, fmt.Errorf("failed to list local database PersistentVolumeClaims: %w", err)
+	}
+
+	policy := backstage.Spec.GetDbDeletionPolicy()
+	if policy == bs.DbDeletionPolicySnapshot {
+		if _, err := r.RESTMapper().RESTMapping(volumeSnapshotGVK.GroupKind(), volumeSnapshotGVK.Version); err != nil {
+			if !meta.IsNoMatchError(err) {
+				return false, fmt.Errorf("failed to discover VolumeSnapshot API: %w", err)
+			}
+			lg.Info("VolumeSnapshot API is not available, local database is retained", "backstage", backstage.Name)
+			policy = bs.DbDeletionPolicyRetain
+		}
+	}
+
+	switch policy {
+	case bs.DbDeletionPolicyDelete:
+		if err := r.deletePvcs(ctx, pvcs.Items); err != nil {
+			return false, err
+		}
+		if err := r.tryToDelete(ctx, &corev1.Secret{}, model.DbSecretDefaultName(backstage.Name), backstage.Namespace); err != nil {
+			return false, err
+		}
+	case bs.DbDeletionPolicySnapshot:
+		ready := true
+		for _, pvc := range pvcs.Items {
+			snapshotReady, err := r.snapshotVolume(ctx, backstage, pvc)
+			if err != nil {
+				return false, err
+			}
+			ready = ready && snapshotReady
+		}
+		// keep the PersistentVolumeClaims until their data are snapshotted
+		if !ready {
+			lg.V(1).Info("waiting for local database snapshots to be ready")
+			return false, nil
+		}
+		if err := r.deletePvcs(ctx, pvcs.Items); err != nil {
+			return false, err
+		}
+		if err := r.orphanDbSecret(ctx, backstage); err != nil {
+			return false, err
+		}
+	default:
+		for i := range pvcs.Items {
+			if err := r.orphan(ctx, &pvcs.Items[i]); err != nil {
+				return false, err
+			}
+		}
+		if err := r.orphanDbSecret(ctx, backstage); err != nil {
+			return false, err
+		}
+	}
+
+	lg.V(1).Info("local database finalized ", "policy", policy)
+
+	controllerutil.RemoveFinalizer(backstage, DbFinalizer)
+	if err := r.Update(ctx, backstage); err != nil {
+		return false, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return true, nil
+}
+
+func (r *BackstageReconciler) deletePvcs(ctx context.Context, pvcs []corev1.PersistentVolumeClaim) error {
+	for i := range pvcs {
+		if err := r.Delete(ctx, &pvcs[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PersistentVolumeClaim %s: %w", pvcs[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// orphanDbSecret removes owner references of the DB Secret, patching it blindly as the Operator does not read Secrets
+func (r *BackstageReconciler) orphanDbSecret(ctx context.Context, backstage *bs.Backstage) error {
+	secret := &corev1.Secret{}
+	secret.SetName(model.DbSecretDefaultName(backstage.Name))
+	secret.SetNamespace(backstage.Namespace)
+	if err := r.Patch(ctx, secret, client.RawPatch(types.MergePatchType, []byte(`{"metadata":{"ownerReferences":null}}`))); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to orphan local database secret: %w", err)
+	}
+	return nil
+}
+
+// orphan removes owner references, so the object survives garbage collection of its owners
+func (r *BackstageReconciler) orphan(ctx context.Context, obj client.Object) error {
+	if len(obj.GetOwnerReferences()) == 0 {
+		return nil
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	obj.SetOwnerReferences(nil)
+	if err := r.Patch(ctx, obj, patch); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to orphan %s: %w", obj.GetName(), err)
+	}
+	return nil
+}
+
+// snapshotVolume creates a VolumeSnapshot of the PersistentVolumeClaim, if not created yet,
+// named after the deletion timestamp of the Backstage CR to stay the same on retries.
+// Returns true if the snapshot is ready to use, error if the snapshot failed
+func (r *BackstageReconciler) snapshotVolume(ctx context.Context, backstage *bs.Backstage, pvc corev1.PersistentVolumeClaim) (bool, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(fmt.Sprintf("%s-%d", pvc.Name, backstage.DeletionTimestamp.Unix()))
+	snapshot.SetNamespace(pvc.Namespace)
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(snapshot), snapshot); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get VolumeSnapshot of %s: %w", pvc.Name, err)
+		}
+		snapshot.SetLabels(utils.SetKubeLabels(nil, backstage.Name))
+		if err := unstructured.SetNestedField(snapshot.Object, pvc.Name, "spec", "source", "persistentVolumeClaimName"); err != nil {
+			return false, fmt.Errorf("failed to build VolumeSnapshot: %w", err)
+		}
+		if err := r.Create(ctx, snapshot); err != nil {
+			return false, fmt.Errorf("failed to create VolumeSnapshot of %s: %w", pvc.Name, err)
+		}
+		log.FromContext(ctx).V(1).Info("create VolumeSnapshot ", snapshot.GetName(), pvc.Name)
+		return false, nil
+	}
+
+	if msg, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		return false, fmt.Errorf("failed to snapshot %s, VolumeSnapshot %s error: %s", pvc.Name, snapshot.GetName(), msg)
+	}
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return ready, nil
+}
@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	bs "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+)
+
+// pinImageDigests pins the images of the containers managed by the Operator (see model.VisitManagedContainers)
+// to the digests, if enabled by Spec.PinImageDigests. The digests recorded in the status are reused while
+// neither the Backstage CR (its generation) nor the default configuration changes,
+// so moving tags (like latest) are resolved again on the next update.
+// The images failed to resolve (for example, of registries requiring authentication) are left unpinned
+// and reported by ImagesPinned condition, so they do not block the deployment
+func (r *BackstageReconciler) pinImageDigests(ctx context.Context, backstage *bs.Backstage, bsModel *model.BackstageModel) error {
+
+	lg := log.FromContext(ctx)
+
+	if !backstage.Spec.PinImageDigests {
+		backstage.Status.ImageDigests = nil
+		backstage.Status.ImageDigestsSource = ""
+		meta.RemoveStatusCondition(&backstage.Status.Conditions, string(bs.BackstageConditionTypeImagesPinned))
+		return nil
+	}
+
+	source, err := utils.HashObject(struct {
+		Generation    int64
+		DefaultConfig map[string]string
+	}{backstage.Generation, bsModel.ExternalConfig.DefaultConfig})
+	if err != nil {
+		return err
+	}
+
+	resolved := map[string]string{}
+	if backstage.Status.ImageDigestsSource == source {
+		for _, d := range backstage.Status.ImageDigests {
+			resolved[d.Image] = d.Digest
+		}
+	}
+
+	current := make([]bs.ImageDigest, 0)
+	failed := map[string]bool{}
+	var failures []string
+	bsModel.VisitManagedContainers(func(container *corev1.Container) {
+		// already pinned
+		if container.Image == "" || strings.Contains(container.Image, "@") || failed[container.Image] {
+			return
+		}
+		digest, ok := resolved[container.Image]
+		if !ok {
+			d, err := r.imageResolver().Resolve(ctx, container.Image)
+			if err != nil {
+				lg.Info("failed to resolve image, it is not pinned", "image", container.Image, "error", err.Error())
+				failed[container.Image] = true
+				failures = append(failures, fmt.Sprintf("%s: %s", container.Image, err))
+				return
+			}
+			lg.V(1).Info("resolve image ", container.Image, d)
+			digest = d
+			resolved[container.Image] = d
+			current = append(current, bs.ImageDigest{Image: container.Image, Digest: digest})
+		} else if !containsImageDigest(current, container.Image) {
+			current = append(current, bs.ImageDigest{Image: container.Image, Digest: digest})
+		}
+		container.Image = fmt.Sprintf("%s@%s", container.Image, digest)
+	})
+
+	if len(failures) > 0 {
+		setStatusCondition(backstage, bs.BackstageConditionTypeImagesPinned, metav1.ConditionFalse, bs.BackstageConditionReasonImageResolveFailed,
+			fmt.Sprintf("images left unpinned, failed to resolve %s", strings.Join(failures, "; ")))
+	} else {
+		setStatusCondition(backstage, bs.BackstageConditionTypeImagesPinned, metav1.ConditionTrue, bs.BackstageConditionReasonImagesPinned, "")
+	}
+
+	backstage.Status.ImageDigests = nil
+	backstage.Status.ImageDigestsSource = ""
+	if len(current) > 0 {
+		backstage.Status.ImageDigests = current
+		backstage.Status.ImageDigestsSource = source
+	}
+	return nil
+}
+
+func (r *BackstageReconciler) imageResolver() utils.ImageResolver {
+	if r.ImageResolver != nil {
+		return r.ImageResolver
+	}
+	return &utils.RegistryResolver{}
+}
+
+func containsImageDigest(digests []bs.ImageDigest, image string) bool {
+	for _, d := range digests {
+		if d.Image == image {
+			return true
+		}
+	}
+	return false
+}
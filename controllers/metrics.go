@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	bs "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsPrefix = "rhdh_backstage_"
+
+var (
+	instanceLabels  = []string{"namespace", "name"}
+	conditionLabels = []string{"namespace", "name", "type", "status"}
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    metricsPrefix + "reconcile_duration_seconds",
+		Help:    "Duration of Backstage reconciliation in seconds",
+		Buckets: prometheus.DefBuckets,
+	}, instanceLabels)
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: metricsPrefix + "reconcile_errors_total",
+		Help: "Number of failed Backstage reconciliations",
+	}, instanceLabels)
+
+	conditionStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: metricsPrefix + "condition",
+		Help: "Status of the Backstage condition, 1 for the current status of the condition type and 0 for others",
+	}, conditionLabels)
+
+	managedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: metricsPrefix + "managed_objects",
+		Help: "Number of runtime objects managed for Backstage",
+	}, instanceLabels)
+
+	enabledDynamicPlugins = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: metricsPrefix + "dynamic_plugins_enabled",
+		Help: "Number of dynamic plugins enabled in Backstage dynamic-plugins.yaml",
+	}, instanceLabels)
+
+	lastSuccessfulApply = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: metricsPrefix + "last_successful_apply_timestamp_seconds",
+		Help: "Unix time of the last successful apply of Backstage runtime objects",
+	}, instanceLabels)
+)
+
+func init() {
+	// registered to the controller-runtime registry, exposed by the manager's metrics endpoint
+	metrics.Registry.MustRegister(reconcileDuration, reconcileErrors, conditionStatus,
+		managedObjects, enabledDynamicPlugins, lastSuccessfulApply)
+}
+
+// observeReconcile records the duration and the result of Backstage reconciliation
+func observeReconcile(name types.NamespacedName, duration time.Duration, err error) {
+	reconcileDuration.WithLabelValues(name.Namespace, name.Name).Observe(duration.Seconds())
+	if err != nil {
+		reconcileErrors.WithLabelValues(name.Namespace, name.Name).Inc()
+	} else {
+		// initialize the counter, so the errors rate is available before the first error
+		reconcileErrors.WithLabelValues(name.Namespace, name.Name)
+	}
+}
+
+// observeConditions records the status of every Backstage condition
+func observeConditions(backstage *bs.Backstage) {
+	for _, cond := range backstage.Status.Conditions {
+		for _, status := range []metav1.ConditionStatus{metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown} {
+			value := 0.0
+			if cond.Status == status {
+				value = 1
+			}
+			conditionStatus.WithLabelValues(backstage.Namespace, backstage.Name, cond.Type, string(status)).Set(value)
+		}
+	}
+}
+
+// observeApply records the successfully applied runtime objects
+func observeApply(backstage *bs.Backstage, objects int, plugins int) {
+	managedObjects.WithLabelValues(backstage.Namespace, backstage.Name).Set(float64(objects))
+	enabledDynamicPlugins.WithLabelValues(backstage.Namespace, backstage.Name).Set(float64(plugins))
+	lastSuccessfulApply.WithLabelValues(backstage.Namespace, backstage.Name).SetToCurrentTime()
+}
+
+// deleteMetrics removes the metrics of deleted Backstage
+func deleteMetrics(name types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": name.Namespace, "name": name.Name}
+	for _, vec := range []interface {
+		DeletePartialMatch(labels prometheus.Labels) int
+	}{reconcileDuration, reconcileErrors, conditionStatus, managedObjects, enabledDynamicPlugins, lastSuccessfulApply} {
+		vec.DeletePartialMatch(labels)
+	}
+}
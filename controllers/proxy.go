@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// name of the cluster-wide OpenShift Proxy
+const clusterProxyName = "cluster"
+
+// clusterProxy returns the proxy configuration of OpenShift cluster (status of the cluster Proxy) if configured,
+// the proxy env variables of the Operator otherwise
+func (r *BackstageReconciler) clusterProxy(ctx context.Context) (model.ProxyConfig, error) {
+	if r.IsOpenShift {
+		proxy := &configv1.Proxy{}
+		if err := r.Get(ctx, types.NamespacedName{Name: clusterProxyName}, proxy); err != nil {
+			// not permitted to read the Proxy with namespace scoped permissions (WATCH_NAMESPACE)
+			if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) && !errors.IsForbidden(err) {
+				return model.ProxyConfig{}, fmt.Errorf("failed to get cluster proxy: %w", err)
+			}
+		} else {
+			config := model.ProxyConfig{
+				HTTPProxy:  proxy.Status.HTTPProxy,
+				HTTPSProxy: proxy.Status.HTTPSProxy,
+				NoProxy:    proxy.Status.NoProxy,
+			}
+			if !config.IsEmpty() {
+				return config, nil
+			}
+		}
+	}
+	return model.ProxyConfigFromEnv(), nil
+}
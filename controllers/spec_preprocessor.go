@@ -39,8 +39,39 @@ func (r *BackstageReconciler) preprocessSpec(ctx context.Context, backstage bs.B
 		AppConfigs:          map[string]corev1.ConfigMap{},
 		ExtraFileConfigMaps: map[string]corev1.ConfigMap{},
 		ExtraEnvConfigMaps:  map[string]corev1.ConfigMap{},
+		RegistryMirrors:     r.RegistryMirrors,
 	}
 
+	// Process Operator default configuration
+	// the ConfigMap is read from the informer's cache, which keeps it in memory
+	if r.DefaultConfigMap.Name != "" {
+		cm := corev1.ConfigMap{}
+		if err := r.Get(ctx, r.DefaultConfigMap, &cm); err != nil {
+			return result, fmt.Errorf("failed to load default configuration %s: %w", r.DefaultConfigMap, err)
+		}
+		result.DefaultConfig = map[string]string{}
+		for key, value := range cm.Data {
+			result.DefaultConfig[key] = value
+		}
+		// keys of the profile override the base ones
+		if bsSpec.Profile != "" {
+			profileCm := corev1.ConfigMap{}
+			if err := r.Get(ctx, r.profileConfigMap(bsSpec.Profile), &profileCm); err != nil {
+				return result, fmt.Errorf("failed to load default configuration profile %s: %w", bsSpec.Profile, err)
+			}
+			for key, value := range profileCm.Data {
+				result.DefaultConfig[key] = value
+			}
+		}
+	}
+
+	// Process cluster proxy configuration
+	proxy, err := r.clusterProxy(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Proxy = proxy
+
 	// Process RawConfig
 	if bsSpec.RawRuntimeConfig != nil {
 		if bsSpec.RawRuntimeConfig.BackstageConfigName != "" {
@@ -113,3 +144,9 @@ func (r *BackstageReconciler) preprocessSpec(ctx context.Context, backstage bs.B
 
 	return result, nil
 }
+
+// profileConfigMap returns the name of ConfigMap containing the default configuration profile,
+// which is the default ConfigMap name suffixed with the profile name
+func (r *BackstageReconciler) profileConfigMap(profile string) types.NamespacedName {
+	return types.NamespacedName{Name: fmt.Sprintf("%s-%s", r.DefaultConfigMap.Name, profile), Namespace: r.DefaultConfigMap.Namespace}
+}
@@ -0,0 +1,127 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// namespaced-role generates the namespace scoped Role (config/rbac-namespaced/role.yaml)
+// from the manager ClusterRole generated by controller-gen (config/rbac/role.yaml),
+// leaving out the rules of cluster scoped resources.
+//
+// Usage: go run ./hack/namespaced-role <ClusterRole file> <Role file>
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const header = `# Code generated by hack/namespaced-role from role.yaml of config/rbac. DO NOT EDIT.
+# Namespace scoped counterpart of the manager ClusterRole for the Operator watching
+# particular namespaces (WATCH_NAMESPACE), it has to be bound in every watched namespace.
+# Cluster scoped resources (persistentvolumes, config.openshift.io proxies) are not available this way.
+`
+
+// cluster scoped resources the Operator works with, by API group ("*" for all the resources of the group)
+var clusterScoped = map[string][]string{
+	"":                    {"persistentvolumes", "namespaces", "nodes"},
+	"config.openshift.io": {"*"},
+}
+
+// role is rbacv1.Role with the metadata the generated file needs only
+type role struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   roleMetadata        `json:"metadata"`
+	Rules      []rbacv1.PolicyRule `json:"rules"`
+}
+
+type roleMetadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: namespaced-role <ClusterRole file> <Role file>")
+		os.Exit(2)
+	}
+	if err := generate(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(clusterRoleFile, roleFile string) error {
+	content, err := os.ReadFile(filepath.Clean(clusterRoleFile))
+	if err != nil {
+		return err
+	}
+	clusterRole := rbacv1.ClusterRole{}
+	if err := yaml.Unmarshal(content, &clusterRole); err != nil {
+		return fmt.Errorf("failed to read %s: %w", clusterRoleFile, err)
+	}
+
+	out := role{
+		APIVersion: rbacv1.SchemeGroupVersion.String(),
+		Kind:       "Role",
+		Metadata: roleMetadata{
+			Name: clusterRole.Name,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       "role",
+				"app.kubernetes.io/instance":   clusterRole.Name,
+				"app.kubernetes.io/component":  "rbac",
+				"app.kubernetes.io/created-by": "backstage-operator",
+				"app.kubernetes.io/part-of":    "backstage-operator",
+				"app.kubernetes.io/managed-by": "kustomize",
+			},
+		},
+		Rules: make([]rbacv1.PolicyRule, 0, len(clusterRole.Rules)),
+	}
+	for _, rule := range clusterRole.Rules {
+		if len(rule.NonResourceURLs) > 0 {
+			continue
+		}
+		resources := make([]string, 0, len(rule.Resources))
+		for _, resource := range rule.Resources {
+			if !isClusterScoped(rule.APIGroups, resource) {
+				resources = append(resources, resource)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		rule.Resources = resources
+		out.Rules = append(out.Rules, rule)
+	}
+
+	generated, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(roleFile, append([]byte(header), generated...), 0o644) // #nosec G306
+}
+
+// isClusterScoped returns true if the resource is cluster scoped in any of the API groups
+func isClusterScoped(apiGroups []string, resource string) bool {
+	for _, group := range apiGroups {
+		for _, r := range clusterScoped[group] {
+			if r == "*" || r == resource {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -135,8 +135,8 @@ var _ = When("create backstage with CR configured", func() {
 			c := podSpec.Containers[0]
 
 			By("checking if app-config volumes are added to PodSpec")
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret("app-config1")).To(BeAddedAsVolumeToPodSpec(podSpec))
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret("app-config2")).To(BeAddedAsVolumeToPodSpec(podSpec))
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, "app-config1")).To(BeAddedAsVolumeToPodSpec(podSpec))
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, "app-config2")).To(BeAddedAsVolumeToPodSpec(podSpec))
 
 			By("checking if app-config volumes are mounted to the Backstage container")
 			g.Expect("/my/mount/path/key11").To(BeMountedToContainer(c))
@@ -151,8 +151,8 @@ var _ = When("create backstage with CR configured", func() {
 			g.Expect("/my/mount/path/key22").NotTo(BeAddedAsArgToContainer(c))
 
 			By("checking if extra-cm-file volumes are added to PodSpec")
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret("cm-file1")).To(BeAddedAsVolumeToPodSpec(podSpec))
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret("cm-file2")).To(BeAddedAsVolumeToPodSpec(podSpec))
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, "cm-file1")).To(BeAddedAsVolumeToPodSpec(podSpec))
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, "cm-file2")).To(BeAddedAsVolumeToPodSpec(podSpec))
 
 			By("checking if extra-cm-file volumes are mounted to the Backstage container")
 			g.Expect("/my/file/path/cm11").To(BeMountedToContainer(c))
@@ -161,8 +161,8 @@ var _ = When("create backstage with CR configured", func() {
 			g.Expect("/my/file/path/cm22").NotTo(BeMountedToContainer(c))
 
 			By("checking if extra-secret-file volumes are added to PodSpec")
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret("secret-file1")).To(BeAddedAsVolumeToPodSpec(podSpec))
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret("secret-file2")).To(BeAddedAsVolumeToPodSpec(podSpec))
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, "secret-file1")).To(BeAddedAsVolumeToPodSpec(podSpec))
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, "secret-file2")).To(BeAddedAsVolumeToPodSpec(podSpec))
 
 			By("checking if extra-secret-file volumes are mounted to the Backstage container")
 			g.Expect("/my/file/path/sec11").To(BeMountedToContainer(c))
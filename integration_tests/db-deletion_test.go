@@ -0,0 +1,274 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration_tests
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	controller "redhat-developer/red-hat-developer-hub-operator/controllers"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = When("delete backstage with local database", func() {
+
+	var (
+		ctx context.Context
+		ns  string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ns = createNamespace(ctx)
+	})
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: ns},
+		})
+	})
+
+	deleteAndReconcile := func(backstageName string) {
+		bs := &bsv1alpha1.Backstage{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, bs)).To(Succeed())
+		Expect(bs.Finalizers).To(ContainElement(controller.DbFinalizer))
+		Expect(k8sClient.Delete(ctx, bs)).To(Succeed())
+
+		_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, &bsv1alpha1.Backstage{})
+			return errors.IsNotFound(err)
+		}, time.Minute, time.Second).Should(BeTrue())
+	}
+
+	// createDbPvc creates the PersistentVolumeClaim as the local database StatefulSet does, owned by the Backstage CR
+	// to check it survives the deletion
+	createDbPvc := func(backstageName string) *corev1.PersistentVolumeClaim {
+		bs := &bsv1alpha1.Backstage{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, bs)).To(Succeed())
+
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "data-" + model.DbStatefulSetName(backstageName) + "-0",
+				Namespace: ns,
+				Labels:    model.DbSelectorLabels(backstageName),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: bsv1alpha1.GroupVersion.String(),
+					Kind:       "Backstage",
+					Name:       bs.Name,
+					UID:        bs.UID,
+				}},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+		return pvc
+	}
+
+	It("retains DB Secret by default", func() {
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{}, ns)
+
+		_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		deleteAndReconcile(backstageName)
+
+		secret := &corev1.Secret{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: model.DbSecretDefaultName(backstageName), Namespace: ns}, secret)
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(secret.OwnerReferences).To(BeEmpty())
+	})
+
+	It("deletes DB Secret with Delete policy", func() {
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{
+			Database: &bsv1alpha1.Database{
+				DeletionPolicy: bsv1alpha1.DbDeletionPolicyDelete,
+			},
+		}, ns)
+
+		_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		deleteAndReconcile(backstageName)
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: model.DbSecretDefaultName(backstageName), Namespace: ns}, &corev1.Secret{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("retains DB PersistentVolumeClaims by default", func() {
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{}, ns)
+
+		_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		pvc := createDbPvc(backstageName)
+
+		deleteAndReconcile(backstageName)
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+		Expect(pvc.OwnerReferences).To(BeEmpty())
+	})
+
+	It("deletes DB PersistentVolumeClaims with Delete policy", func() {
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{
+			Database: &bsv1alpha1.Database{
+				DeletionPolicy: bsv1alpha1.DbDeletionPolicyDelete,
+			},
+		}, ns)
+
+		_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		pvc := createDbPvc(backstageName)
+
+		deleteAndReconcile(backstageName)
+
+		// PersistentVolumeClaim is marked for deletion, the kubernetes.io/pvc-protection finalizer may keep it for a while
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)
+			return errors.IsNotFound(err) || pvc.DeletionTimestamp != nil
+		}, time.Minute, time.Second).Should(BeTrue())
+	})
+
+	When("Snapshot policy", func() {
+
+		var (
+			backstageName string
+			pvc           *corev1.PersistentVolumeClaim
+			snapshot      *unstructured.Unstructured
+		)
+
+		BeforeEach(func() {
+			if *testEnv.UseExistingCluster {
+				Skip("VolumeSnapshots are made ready by the test instead of the snapshot controller")
+			}
+
+			backstageName = createBackstage(ctx, bsv1alpha1.BackstageSpec{
+				Database: &bsv1alpha1.Database{
+					DeletionPolicy: bsv1alpha1.DbDeletionPolicySnapshot,
+				},
+			}, ns)
+
+			_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			pvc = createDbPvc(backstageName)
+
+			bs := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, bs)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, bs)).To(Succeed())
+
+			By("creating the VolumeSnapshot and keeping the PersistentVolumeClaim until it is ready")
+			result, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			snapshots := &unstructured.UnstructuredList{}
+			snapshots.SetGroupVersionKind(schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshotList"})
+			Expect(k8sClient.List(ctx, snapshots, client.InNamespace(ns))).To(Succeed())
+			Expect(snapshots.Items).To(HaveLen(1))
+			snapshot = &snapshots.Items[0]
+			source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+			Expect(source).To(Equal(pvc.Name))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+			Expect(pvc.DeletionTimestamp).To(BeNil())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, &bsv1alpha1.Backstage{})).To(Succeed())
+		})
+
+		It("deletes DB PersistentVolumeClaims when the VolumeSnapshots are ready", func() {
+
+			Expect(unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse")).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, snapshot)).To(Succeed())
+
+			_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, &bsv1alpha1.Backstage{})
+				return errors.IsNotFound(err)
+			}, time.Minute, time.Second).Should(BeTrue())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)
+				return errors.IsNotFound(err) || pvc.DeletionTimestamp != nil
+			}, time.Minute, time.Second).Should(BeTrue())
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: model.DbSecretDefaultName(backstageName), Namespace: ns}, secret)).To(Succeed())
+			Expect(secret.OwnerReferences).To(BeEmpty())
+		})
+
+		It("fails and keeps DB PersistentVolumeClaims if the VolumeSnapshot fails", func() {
+
+			Expect(unstructured.SetNestedField(snapshot.Object, "no space left", "status", "error", "message")).To(Succeed())
+			Expect(k8sClient.Status().Update(ctx, snapshot)).To(Succeed())
+
+			_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no space left"))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+			Expect(pvc.DeletionTimestamp).To(BeNil())
+			bs := &bsv1alpha1.Backstage{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backstageName, Namespace: ns}, bs)).To(Succeed())
+			Expect(bs.Finalizers).To(ContainElement(controller.DbFinalizer))
+		})
+	})
+})
@@ -109,7 +109,7 @@ var _ = When("create default backstage", func() {
 			g.Expect(err).ShouldNot(HaveOccurred())
 
 			By("mounting Volume defined in default app-config")
-			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(model.AppConfigDefaultName(backstageName))).
+			g.Expect(utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, model.AppConfigDefaultName(backstageName))).
 				To(BeAddedAsVolumeToPodSpec(deploy.Spec.Template.Spec))
 
 			By("setting Backstage status")
@@ -215,4 +215,58 @@ spec:
 		}, time.Minute, time.Second).Should(Succeed())
 
 	})
+
+	It("creates and prunes extra objects defined in raw configuration", func() {
+
+		generateConfigMap(ctx, k8sClient, "bsraw", ns, map[string]string{
+			"extra-sa.yaml": `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-sa
+`,
+		})
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{
+			RawRuntimeConfig: &bsv1alpha1.RuntimeConfig{
+				BackstageConfigName: "bsraw",
+			},
+		}, ns)
+
+		_, err := NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		By("creating ServiceAccount with instance labels")
+		sa := &corev1.ServiceAccount{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: "my-sa"}, sa)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sa.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/instance", backstageName))
+		Expect(sa.GetOwnerReferences()).To(HaveLen(1))
+
+		bs := &bsv1alpha1.Backstage{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: backstageName}, bs)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bs.Status.ExtraObjects).To(HaveLen(1))
+
+		By("deleting ServiceAccount removed from raw configuration")
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: "bsraw"}, cm)
+		Expect(err).ShouldNot(HaveOccurred())
+		cm.Data = map[string]string{}
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+
+		_, err = NewTestBackstageReconciler(ns).ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: "my-sa"}, sa)
+		Expect(err).Should(HaveOccurred())
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: backstageName}, bs)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bs.Status.ExtraObjects).To(BeEmpty())
+	})
 })
@@ -0,0 +1,168 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration_tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeResolver resolves every image to the same digest counting the calls, fails if err is set
+type fakeResolver struct {
+	calls int
+	err   error
+}
+
+func (f *fakeResolver) Resolve(_ context.Context, _ string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return "sha256:0123456789", nil
+}
+
+var _ = When("create backstage with pinned image digests", func() {
+
+	var (
+		ctx context.Context
+		ns  string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ns = createNamespace(ctx)
+	})
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: ns},
+		})
+	})
+
+	It("pins images to digests and resolves them again on the Backstage CR change", func() {
+
+		if *testEnv.UseExistingCluster {
+			Skip("fake image resolver is used by the test reconciler only")
+		}
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{
+			PinImageDigests: true,
+			Database: &bsv1alpha1.Database{
+				EnableLocalDb: ptr.To(false),
+			},
+			Application: &bsv1alpha1.Application{
+				ExtraContainers: []corev1.Container{{Name: "sidecar", Image: "busybox"}},
+			},
+		}, ns)
+
+		resolver := &fakeResolver{}
+		reconciler := NewTestBackstageReconciler(ns)
+		reconciler.rec.ImageResolver = resolver
+
+		_, err := reconciler.ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		deploy := &appsv1.Deployment{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, deploy)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, c := range append(deploy.Spec.Template.Spec.InitContainers, deploy.Spec.Template.Spec.Containers...) {
+			if c.Name == "sidecar" {
+				// user containers are not pinned
+				Expect(c.Image).To(Equal("busybox"))
+				continue
+			}
+			Expect(strings.HasSuffix(c.Image, "@sha256:0123456789")).To(BeTrue(), c.Image)
+		}
+
+		bs := &bsv1alpha1.Backstage{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: backstageName}, bs)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bs.Status.ImageDigests).NotTo(BeEmpty())
+		Expect(meta.IsStatusConditionTrue(bs.Status.Conditions, string(bsv1alpha1.BackstageConditionTypeImagesPinned))).To(BeTrue())
+		calls := resolver.calls
+
+		By("reusing the digests recorded in the status")
+		_, err = reconciler.ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(resolver.calls).To(Equal(calls))
+
+		By("resolving the digests again when the Backstage CR changes")
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: backstageName}, bs)).To(Succeed())
+		bs.Spec.Application = &bsv1alpha1.Application{Replicas: ptr.To(int32(2))}
+		Expect(k8sClient.Update(ctx, bs)).To(Succeed())
+		_, err = reconciler.ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(resolver.calls).To(BeNumerically(">", calls))
+	})
+
+	It("leaves images unpinned if they can not be resolved", func() {
+
+		if *testEnv.UseExistingCluster {
+			Skip("fake image resolver is used by the test reconciler only")
+		}
+
+		backstageName := createBackstage(ctx, bsv1alpha1.BackstageSpec{
+			PinImageDigests: true,
+			Database: &bsv1alpha1.Database{
+				EnableLocalDb: ptr.To(false),
+			},
+		}, ns)
+
+		reconciler := NewTestBackstageReconciler(ns)
+		reconciler.rec.ImageResolver = &fakeResolver{err: fmt.Errorf("401 Unauthorized")}
+
+		_, err := reconciler.ReconcileAny(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: backstageName, Namespace: ns},
+		})
+		Expect(err).To(Not(HaveOccurred()))
+
+		deploy := &appsv1.Deployment{}
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: model.DeploymentName(backstageName)}, deploy)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, c := range append(deploy.Spec.Template.Spec.InitContainers, deploy.Spec.Template.Spec.Containers...) {
+			Expect(c.Image).NotTo(ContainSubstring("@"))
+		}
+
+		bs := &bsv1alpha1.Backstage{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: backstageName}, bs)).To(Succeed())
+		Expect(bs.Status.ImageDigests).To(BeEmpty())
+		cond := meta.FindStatusCondition(bs.Status.Conditions, string(bsv1alpha1.BackstageConditionTypeImagesPinned))
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(string(bsv1alpha1.BackstageConditionReasonImageResolveFailed)))
+		Expect(cond.Message).To(ContainSubstring("401 Unauthorized"))
+	})
+})
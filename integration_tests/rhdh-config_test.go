@@ -88,7 +88,7 @@ var _ = When("create default backstage", func() {
 			g.Expect(initCont.VolumeMounts).To(HaveLen(3))
 			g.Expect(initCont.VolumeMounts[2].MountPath).To(Equal("/opt/app-root/src/dynamic-plugins.yaml"))
 			g.Expect(initCont.VolumeMounts[2].Name).
-				To(Equal(utils.GenerateVolumeNameFromCmOrSecret(model.DynamicPluginsDefaultName(backstageName))))
+				To(Equal(utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, model.DynamicPluginsDefaultName(backstageName))))
 			g.Expect(initCont.VolumeMounts[2].SubPath).To(Equal(model.DynamicPluginsFile))
 
 		}, time.Minute, time.Second).Should(Succeed())
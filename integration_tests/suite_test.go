@@ -98,6 +98,11 @@ var _ = BeforeSuite(func() {
 		}
 	}
 
+	if !*testEnv.UseExistingCluster {
+		// third party APIs the Operator works with, if installed
+		testEnv.CRDDirectoryPaths = append(testEnv.CRDDirectoryPaths, filepath.Join("testdata", "crd"))
+	}
+
 	if val, ok := os.LookupEnv("USE_EXISTING_CONTROLLER"); ok {
 		boolValue, err := strconv.ParseBool(val)
 		if err == nil {
@@ -183,6 +188,9 @@ func NewTestBackstageReconciler(namespace string) *TestBackstageReconciler {
 		OwnsRuntime: true,
 		// let's set it explicitly to avoid misunderstanding
 		IsOpenShift: isOpenshift,
+		// the tests reconcile once and do not requeue,
+		// besides there is no StatefulSet controller in EnvTest, so local DB is never ready
+		IgnoreReadiness: true,
 	}, namespace: namespace}
 }
 
@@ -19,16 +19,24 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
+	"time"
+	// embedded time zone database for Backstage schedules, the Operator image may have none
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -36,7 +44,9 @@ import (
 
 	backstageiov1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
 	controller "redhat-developer/red-hat-developer-hub-operator/controllers"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/model"
 
+	configv1 "github.com/openshift/api/config/v1"
 	openshift "github.com/openshift/api/route/v1"
 	//+kubebuilder:scaffold:imports
 )
@@ -52,6 +62,8 @@ func init() {
 	utilruntime.Must(backstageiov1alpha1.AddToScheme(scheme))
 
 	utilruntime.Must(openshift.Install(scheme))
+
+	utilruntime.Must(configv1.Install(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -60,6 +72,11 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var ownRuntime bool
+	var defaultConfigMap string
+	var registryMirrors string
+	var watchNamespaces string
+	var maxConcurrentReconciles int
+	var retryBaseDelay, retryMaxDelay, syncPeriod time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -67,6 +84,18 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&ownRuntime, "own-runtime", true, "Making Backstage Controller own runtime objects. "+
 		"If 'true' - all runtime objects created by Controller will be syncing with desired state configured by Controller")
+	flag.StringVar(&defaultConfigMap, "default-config-map", "backstage-default-config", "The name of ConfigMap containing default configuration "+
+		"in the Operator namespace (env.POD_NAMESPACE). If the namespace is unknown, default configuration is read from the default-config directory.")
+	flag.StringVar(&registryMirrors, "registry-mirrors", "", "Comma separated list of source=mirror prefix rewrites applied to container images "+
+		"and dynamic plugin packages, for example 'quay.io=mirror.example.com/quay'. Extended with registry-mirrors.yaml key of default configuration.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", os.Getenv("WATCH_NAMESPACE"), "Comma separated list of namespaces "+
+		"the Backstage CRs are watched in (env.WATCH_NAMESPACE by default). If empty, all the namespaces are watched.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "The maximum number of Backstage CRs reconciled concurrently.")
+	flag.DurationVar(&retryBaseDelay, "retry-base-delay", 5*time.Millisecond, "The initial delay of exponential backoff "+
+		"of failed reconciliation retries, doubled on every failure of the same Backstage CR.")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", 1000*time.Second, "The maximum delay of exponential backoff of failed reconciliation retries.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour, "The period every watched object is reconciled with, "+
+		"even if it has not changed.")
 
 	opts := zap.Options{
 		Development: true,
@@ -76,8 +105,17 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	defaultConfig := types.NamespacedName{}
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		defaultConfig = types.NamespacedName{Name: defaultConfigMap, Namespace: ns}
+	}
+
+	namespaces := parseNamespaces(watchNamespaces)
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOptions(namespaces, defaultConfig.Namespace, syncPeriod),
+		Client: clientOptions(namespaces),
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
@@ -110,11 +148,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	mirrors, err := model.ParseRegistryMirrors(registryMirrors)
+	if err != nil {
+		setupLog.Error(err, "invalid --registry-mirrors flag")
+		os.Exit(1)
+	}
+
 	if err = (&controller.BackstageReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		OwnsRuntime: ownRuntime,
-		IsOpenShift: isOpenShift,
+		Client:           mgr.GetClient(),
+		APIReader:        mgr.GetAPIReader(),
+		Scheme:           mgr.GetScheme(),
+		OwnsRuntime:      ownRuntime,
+		IsOpenShift:      isOpenShift,
+		DefaultConfigMap: defaultConfig,
+		RegistryMirrors:  mirrors,
+
+		// the cluster Proxy can be watched with cluster-wide permissions only
+		WatchClusterProxy: len(namespaces) == 0,
+
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RetryBaseDelay:          retryBaseDelay,
+		RetryMaxDelay:           retryMaxDelay,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Backstage")
 		os.Exit(1)
@@ -133,7 +187,11 @@ func main() {
 	setupLog.Info("starting manager with parameters: ",
 		"own-runtime", ownRuntime,
 		"env.LOCALBIN", os.Getenv("LOCALBIN"),
+		"default-config", defaultConfig.String(),
 		"isOpenShift", isOpenShift,
+		"watch-namespaces", namespaces,
+		"max-concurrent-reconciles", maxConcurrentReconciles,
+		"sync-period", syncPeriod,
 	)
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -163,3 +221,45 @@ func isOpenshift() (bool, error) {
 
 	return false, nil
 }
+
+// parseNamespaces parses comma separated list of namespaces
+func parseNamespaces(value string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// cacheOptions restricts the manager cache to the watched namespaces (if any),
+// ConfigMaps are cached in the Operator namespace as well, to watch the default configuration
+func cacheOptions(namespaces []string, operatorNamespace string, syncPeriod time.Duration) cache.Options {
+	opts := cache.Options{SyncPeriod: &syncPeriod}
+	if len(namespaces) == 0 {
+		return opts
+	}
+	watched := map[string]cache.Config{}
+	for _, ns := range namespaces {
+		watched[ns] = cache.Config{}
+	}
+	opts.DefaultNamespaces = watched
+	if _, ok := watched[operatorNamespace]; operatorNamespace != "" && !ok {
+		configMaps := map[string]cache.Config{operatorNamespace: {}}
+		for ns := range watched {
+			configMaps[ns] = cache.Config{}
+		}
+		opts.ByObject = map[client.Object]cache.ByObject{&corev1.ConfigMap{}: {Namespaces: configMaps}}
+	}
+	return opts
+}
+
+// clientOptions reads the cluster scoped objects directly if the namespaces are watched,
+// the cache would not sync them without cluster-wide permissions
+func clientOptions(namespaces []string) client.Options {
+	if len(namespaces) == 0 {
+		return client.Options{}
+	}
+	return client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&configv1.Proxy{}}}}
+}
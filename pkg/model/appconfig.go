@@ -39,6 +39,7 @@ type AppConfig struct {
 	ConfigMap *corev1.ConfigMap
 	MountPath string
 	Key       string
+	MountMode bsv1alpha1.MountMode
 }
 
 func init() {
@@ -65,6 +66,7 @@ func addAppConfigs(spec bsv1alpha1.BackstageSpec, deployment *appsv1.Deployment,
 			ConfigMap: &cm,
 			MountPath: mp,
 			Key:       configMap.Key,
+			MountMode: configMap.MountMode,
 		}
 		ac.updatePod(deployment)
 	}
@@ -110,10 +112,8 @@ func (b *AppConfig) setMetaInfo(backstageName string) {
 // it contrubutes to Volumes, container.VolumeMounts and contaiter.Args
 func (b *AppConfig) updatePod(deployment *appsv1.Deployment) {
 
-	utils.MountFilesFrom(&deployment.Spec.Template.Spec, &deployment.Spec.Template.Spec.Containers[0], utils.ConfigMapObjectKind,
-		b.ConfigMap.Name, b.MountPath, b.Key, b.ConfigMap.Data)
-
-	fileDir := b.MountPath
+	fileDir := mountFiles(deployment, utils.ConfigMapObjectKind, b.ConfigMap.Name, b.MountPath, b.Key, b.ConfigMap.Data,
+		b.MountMode, appConfigProjectedDir)
 	for file := range b.ConfigMap.Data {
 		if b.Key == "" || b.Key == file {
 			deployment.Spec.Template.Spec.Containers[0].Args =
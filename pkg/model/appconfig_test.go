@@ -87,7 +87,7 @@ func TestDefaultAppConfig(t *testing.T) {
 
 	assert.Equal(t, 1, len(deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts))
 	assert.Contains(t, deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath, defaultMountDir)
-	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret(AppConfigDefaultName(bs.Name)), deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].Name)
+	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, AppConfigDefaultName(bs.Name)), deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].Name)
 	assert.Equal(t, 2, len(deployment.deployment.Spec.Template.Spec.Containers[0].Args))
 	assert.Equal(t, 1, len(deployment.deployment.Spec.Template.Spec.Volumes))
 
@@ -159,3 +159,79 @@ func TestDefaultAndSpecifiedAppConfig(t *testing.T) {
 	//t.Log(">>>>>>>>>>>>>>>>", )
 
 }
+
+func TestAppConfigMountModes(t *testing.T) {
+
+	bs := *appConfigTestBackstage.DeepCopy()
+	bs.Spec.Application.AppConfig.ConfigMaps = []bsv1alpha1.ObjectKeyRef{
+		{Name: appConfigTestCm.Name, MountMode: bsv1alpha1.MountModeDirectory},
+		{Name: appConfigTestCm2.Name, MountMode: bsv1alpha1.MountModeProjected},
+		{Name: appConfigTestCm3.Name, Key: "conf31.yaml", MountMode: bsv1alpha1.MountModeProjected},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.AppConfigs = map[string]corev1.ConfigMap{appConfigTestCm.Name: appConfigTestCm, appConfigTestCm2.Name: appConfigTestCm2,
+		appConfigTestCm3.Name: appConfigTestCm3}
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	deployment := model.backstageDeployment
+	podSpec := deployment.podSpec()
+
+	// one directory and one projected volume, no subPath
+	assert.Equal(t, 2, len(podSpec.Volumes))
+	assert.Equal(t, 2, len(deployment.container().VolumeMounts))
+	for _, vm := range deployment.container().VolumeMounts {
+		assert.Empty(t, vm.SubPath)
+	}
+	assert.Equal(t, "/my/path/app-config1", deployment.container().VolumeMounts[0].MountPath)
+	assert.Equal(t, "/my/path/app-config", deployment.container().VolumeMounts[1].MountPath)
+	assert.Equal(t, 2, len(podSpec.Volumes[1].Projected.Sources))
+	assert.Equal(t, "conf31.yaml", podSpec.Volumes[1].Projected.Sources[1].ConfigMap.Items[0].Key)
+
+	args := deployment.container().Args
+	assert.Equal(t, 8, len(args))
+	assert.Contains(t, args, "/my/path/app-config1/conf.yaml")
+	assert.Contains(t, args, "/my/path/app-config/conf21.yaml")
+	assert.Contains(t, args, "/my/path/app-config/conf31.yaml")
+}
+
+func TestAppConfigProjectedDuplicateFiles(t *testing.T) {
+
+	bs := *appConfigTestBackstage.DeepCopy()
+	bs.Spec.Application.AppConfig.ConfigMaps = []bsv1alpha1.ObjectKeyRef{
+		{Name: appConfigTestCm2.Name, MountMode: bsv1alpha1.MountModeProjected},
+		{Name: "app-config4", MountMode: bsv1alpha1.MountModeProjected},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.AppConfigs = map[string]corev1.ConfigMap{appConfigTestCm2.Name: appConfigTestCm2,
+		"app-config4": {ObjectMeta: metav1.ObjectMeta{Name: "app-config4", Namespace: "ns123"}, Data: map[string]string{"conf22.yaml": ""}}}
+
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "file conf22.yaml is defined in both ConfigMap app-config2 and ConfigMap app-config4")
+
+	// no collision if only the other key is projected
+	bs.Spec.Application.AppConfig.ConfigMaps[0].Key = "conf21.yaml"
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+}
+
+func TestProjectedDifferentMountPaths(t *testing.T) {
+
+	bs := *appConfigTestBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.AppConfigs = map[string]corev1.ConfigMap{appConfigTestCm2.Name: appConfigTestCm2, appConfigTestCm3.Name: appConfigTestCm3}
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	deployment := model.backstageDeployment.deployment
+	mountFiles(deployment, utils.ConfigMapObjectKind, appConfigTestCm2.Name, "/my/path", "", appConfigTestCm2.Data,
+		bsv1alpha1.MountModeProjected, appConfigProjectedDir)
+	assert.NoError(t, validateProjectedVolumes(&deployment.Spec.Template.Spec, model))
+
+	mountFiles(deployment, utils.ConfigMapObjectKind, appConfigTestCm3.Name, "/other/path", "", appConfigTestCm3.Data,
+		bsv1alpha1.MountModeProjected, appConfigProjectedDir)
+	assert.ErrorContains(t, validateProjectedVolumes(&deployment.Spec.Template.Spec, model),
+		"must have the same mount path, found /my/path/app-config and /other/path/app-config")
+}
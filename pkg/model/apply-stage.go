@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyStage is the group of runtime objects applied together,
+// the stages are applied in ascending order, each one after the objects of previous stages are ready
+type ApplyStage int
+
+const (
+	// ConfigStage Secrets, ConfigMaps and other objects the Backstage Pod and database depend on,
+	// including extra and extension objects
+	ConfigStage ApplyStage = iota
+	// DatabaseStage local database StatefulSet and Service
+	DatabaseStage
+	// BackstageStage Backstage Deployment, applied when the local database is ready
+	BackstageStage
+	// ExposureStage Backstage Service and Route
+	ExposureStage
+)
+
+// ReadinessGate is implemented by the runtime objects the next ApplyStage waits for
+type ReadinessGate interface {
+	// IsReady returns true if the live (cluster) state of the object is ready
+	IsReady(live client.Object) bool
+}
+
+// ApplyStageOf returns the ApplyStage of the runtime object
+func ApplyStageOf(obj RuntimeObject) ApplyStage {
+	switch obj.(type) {
+	case *DbStatefulSet, *DbService:
+		return DatabaseStage
+	case *BackstageDeployment:
+		return BackstageStage
+	case *BackstageService, *BackstageRoute:
+		return ExposureStage
+	default:
+		return ConfigStage
+	}
+}
+
+// ApplyStages groups the runtime objects of the model by ApplyStage, in ascending order
+func (m *BackstageModel) ApplyStages() [][]RuntimeObject {
+	var stages [][]RuntimeObject
+	for i, obj := range m.RuntimeObjects {
+		if i == 0 || ApplyStageOf(obj) != ApplyStageOf(m.RuntimeObjects[i-1]) {
+			stages = append(stages, nil)
+		}
+		stages[len(stages)-1] = append(stages[len(stages)-1], obj)
+	}
+	return stages
+}
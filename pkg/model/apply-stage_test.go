@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyStages(t *testing.T) {
+
+	bs := bsv1alpha1.Backstage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bs",
+			Namespace: "ns123",
+		},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("app-config.yaml", "raw-app-config.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	stages := model.ApplyStages()
+	assert.Equal(t, 4, len(stages))
+	expected := []ApplyStage{ConfigStage, DatabaseStage, BackstageStage, ExposureStage}
+	total := 0
+	for i, stage := range stages {
+		for _, obj := range stage {
+			assert.Equal(t, expected[i], ApplyStageOf(obj))
+		}
+		total += len(stage)
+	}
+	assert.Equal(t, len(model.RuntimeObjects), total)
+
+	assert.Contains(t, stages[0], model.LocalDbSecret)
+	assert.Contains(t, stages[1], model.localDbStatefulSet)
+	assert.Equal(t, []RuntimeObject{model.backstageDeployment}, stages[2])
+	assert.Equal(t, []RuntimeObject{model.backstageService}, stages[3])
+}
+
+func TestDbStatefulSetReadiness(t *testing.T) {
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(1))},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1},
+	}
+	db := &DbStatefulSet{}
+
+	// previous generation ready
+	assert.False(t, db.IsReady(sts))
+
+	sts.Status.ObservedGeneration = 2
+	sts.Status.ReadyReplicas = 0
+	assert.False(t, db.IsReady(sts))
+
+	sts.Status.ReadyReplicas = 1
+	assert.True(t, db.IsReady(sts))
+
+	assert.False(t, db.IsReady(&appsv1.Deployment{}))
+}
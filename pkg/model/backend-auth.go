@@ -0,0 +1,205 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BackendSecretRotationAnnotation of Backstage CR requests the rotation of generated backend auth secret,
+	// the secret is regenerated every time the value changes
+	BackendSecretRotationAnnotation = "rhdh.redhat.com/rotate-backend-secret"
+	// the value of BackendSecretRotationAnnotation is copied to the Pod template, so the Pods are restarted on rotation,
+	// and to the Secret, so the same rotation is never handled twice
+	backendSecretRotationPodAnnotation = "rhdh.redhat.com/backend-secret-rotation"
+
+	backendSecretEnvVar      = "BACKEND_SECRET"
+	backendAuthAppConfigFile = "app-config.backend-auth.yaml"
+)
+
+type BackendAuthSecretFactory struct{}
+
+func (f BackendAuthSecretFactory) newBackstageObject() RuntimeObject {
+	return &BackendAuthSecret{}
+}
+
+// BackendAuthSecret is the Secret with randomly generated BACKEND_SECRET used for Backstage service-to-service auth.
+// The Secret is created once and not updated unless the rotation is requested
+type BackendAuthSecret struct {
+	secret *corev1.Secret
+	// value of BackendSecretRotationAnnotation
+	rotation string
+}
+
+type BackendAuthAppConfigFactory struct{}
+
+func (f BackendAuthAppConfigFactory) newBackstageObject() RuntimeObject {
+	return &BackendAuthAppConfig{}
+}
+
+// BackendAuthAppConfig is the app-config fragment referencing BACKEND_SECRET as the backend auth key
+type BackendAuthAppConfig struct {
+	ConfigMap *corev1.ConfigMap
+}
+
+func init() {
+	registerConfig("backend-auth-secret.yaml", BackendAuthSecretFactory{})
+	// goes after the default app-config, so the key takes precedence
+	registerConfig("backend-auth-app-config.yaml", BackendAuthAppConfigFactory{}, "app-config.yaml", "backend-auth-secret.yaml")
+}
+
+func BackendAuthSecretName(backstageName string) string {
+	return utils.GenerateRuntimeObjectName(backstageName, "backstage-backend-auth")
+}
+
+func BackendAuthAppConfigName(backstageName string) string {
+	return utils.GenerateRuntimeObjectName(backstageName, "backstage-backend-auth-appconfig")
+}
+
+// BackendSecretRotation returns the value of BackendSecretRotationAnnotation of Backstage CR
+func BackendSecretRotation(backstage bsv1alpha1.Backstage) string {
+	return backstage.GetAnnotations()[BackendSecretRotationAnnotation]
+}
+
+// Rotation returns the value of BackendSecretRotationAnnotation of Backstage CR, empty if the rotation is not requested
+func (b *BackendAuthSecret) Rotation() string {
+	return b.rotation
+}
+
+// IsRotationRequested returns true if the value of BackendSecretRotationAnnotation differs from the one
+// the existing (live) Secret was last rotated for, so the Secret has to be updated
+func (b *BackendAuthSecret) IsRotationRequested(live metav1.Object) bool {
+	return b.rotation != "" && live.GetAnnotations()[backendSecretRotationPodAnnotation] != b.rotation
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthSecret) Object() client.Object {
+	return b.secret
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthSecret) setObject(obj client.Object) {
+	b.secret = nil
+	if obj != nil {
+		b.secret = obj.(*corev1.Secret)
+	}
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthSecret) EmptyObject() client.Object {
+	return &corev1.Secret{}
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthSecret) addToModel(model *BackstageModel, backstage bsv1alpha1.Backstage) (bool, error) {
+	if b.secret == nil {
+		return false, nil
+	}
+	b.rotation = BackendSecretRotation(backstage)
+
+	model.backendAuthSecret = b
+	model.setRuntimeObject(b)
+	return true, nil
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthSecret) validate(_ *BackstageModel, _ bsv1alpha1.Backstage) error {
+	secret, err := utils.GeneratePassword(24)
+	if err != nil {
+		return fmt.Errorf("failed to generate backend secret: %w", err)
+	}
+	b.secret.StringData = map[string]string{backendSecretEnvVar: secret}
+	if b.rotation != "" {
+		metav1.SetMetaDataAnnotation(&b.secret.ObjectMeta, backendSecretRotationPodAnnotation, b.rotation)
+	}
+	return nil
+}
+
+func (b *BackendAuthSecret) setMetaInfo(backstageName string) {
+	b.secret.SetName(BackendAuthSecretName(backstageName))
+}
+
+// implementation of BackstagePodContributor interface
+func (b *BackendAuthSecret) updatePod(deployment *appsv1.Deployment) {
+	utils.AddEnvVarsFrom(&deployment.Spec.Template.Spec.Containers[0], utils.SecretObjectKind, b.secret.Name, backendSecretEnvVar)
+	if b.rotation != "" {
+		metav1.SetMetaDataAnnotation(&deployment.Spec.Template.ObjectMeta, backendSecretRotationPodAnnotation, b.rotation)
+	}
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthAppConfig) Object() client.Object {
+	return b.ConfigMap
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthAppConfig) setObject(obj client.Object) {
+	b.ConfigMap = nil
+	if obj != nil {
+		b.ConfigMap = obj.(*corev1.ConfigMap)
+	}
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthAppConfig) EmptyObject() client.Object {
+	return &corev1.ConfigMap{}
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthAppConfig) addToModel(model *BackstageModel, _ bsv1alpha1.Backstage) (bool, error) {
+	if model.backendAuthSecret == nil {
+		return false, nil
+	}
+	// generated if not configured
+	if b.ConfigMap == nil || b.ConfigMap.Data[backendAuthAppConfigFile] == "" {
+		if b.ConfigMap == nil {
+			b.ConfigMap = &corev1.ConfigMap{}
+		}
+		b.ConfigMap.Data = map[string]string{backendAuthAppConfigFile: fmt.Sprintf(`backend:
+  auth:
+    keys:
+      - secret: ${%s}
+`, backendSecretEnvVar)}
+	}
+	model.setRuntimeObject(b)
+	return true, nil
+}
+
+// implementation of RuntimeObject interface
+func (b *BackendAuthAppConfig) validate(_ *BackstageModel, _ bsv1alpha1.Backstage) error {
+	return nil
+}
+
+func (b *BackendAuthAppConfig) setMetaInfo(backstageName string) {
+	b.ConfigMap.SetName(BackendAuthAppConfigName(backstageName))
+}
+
+// implementation of BackstagePodContributor interface
+func (b *BackendAuthAppConfig) updatePod(deployment *appsv1.Deployment) {
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	utils.MountFilesFrom(&deployment.Spec.Template.Spec, container, utils.ConfigMapObjectKind, b.ConfigMap.Name, defaultMountDir,
+		backendAuthAppConfigFile, b.ConfigMap.Data)
+	container.Args = append(container.Args, "--config", filepath.Join(defaultMountDir, backendAuthAppConfigFile))
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackendAuthSecret(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("backend-auth-secret.yaml", "backend-auth-secret.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, model.backendAuthSecret)
+	secret := model.backendAuthSecret.secret
+	assert.Equal(t, BackendAuthSecretName(bs.Name), secret.Name)
+	assert.NotEmpty(t, secret.StringData[backendSecretEnvVar])
+	assert.Empty(t, model.backendAuthSecret.Rotation())
+	assert.False(t, model.backendAuthSecret.IsRotationRequested(&metav1.ObjectMeta{}))
+
+	var appConfig *BackendAuthAppConfig
+	for _, obj := range model.RuntimeObjects {
+		if ac, ok := obj.(*BackendAuthAppConfig); ok {
+			appConfig = ac
+		}
+	}
+	assert.NotNil(t, appConfig)
+	assert.Equal(t, BackendAuthAppConfigName(bs.Name), appConfig.ConfigMap.Name)
+	assert.Contains(t, appConfig.ConfigMap.Data[backendAuthAppConfigFile], "secret: ${BACKEND_SECRET}")
+
+	container := model.backstageDeployment.container()
+	assert.Contains(t, container.Args, defaultMountDir+"/"+backendAuthAppConfigFile)
+	found := false
+	for _, env := range container.Env {
+		if env.Name == backendSecretEnvVar {
+			found = true
+			assert.Equal(t, secret.Name, env.ValueFrom.SecretKeyRef.Name)
+			assert.Equal(t, backendSecretEnvVar, env.ValueFrom.SecretKeyRef.Key)
+		}
+	}
+	assert.True(t, found)
+	assert.Empty(t, model.backstageDeployment.deployment.Spec.Template.Annotations[backendSecretRotationPodAnnotation])
+}
+
+func TestBackendAuthSecretRotation(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.SetAnnotations(map[string]string{BackendSecretRotationAnnotation: "1"})
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("backend-auth-secret.yaml", "backend-auth-secret.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.True(t, model.backendAuthSecret.IsRotationRequested(&metav1.ObjectMeta{}))
+	// Pods restarted with the new secret
+	assert.Equal(t, "1", model.backstageDeployment.deployment.Spec.Template.Annotations[backendSecretRotationPodAnnotation])
+	// the rotation is recorded in the Secret
+	assert.Equal(t, "1", model.backendAuthSecret.secret.Annotations[backendSecretRotationPodAnnotation])
+
+	// already rotated, even if the status is not updated
+	live := &metav1.ObjectMeta{Annotations: map[string]string{backendSecretRotationPodAnnotation: "1"}}
+	assert.False(t, model.backendAuthSecret.IsRotationRequested(live))
+
+	// rotated again
+	bs.SetAnnotations(map[string]string{BackendSecretRotationAnnotation: "2"})
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.True(t, model.backendAuthSecret.IsRotationRequested(live))
+}
+
+func TestNoBackendAuthSecret(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Nil(t, model.backendAuthSecret)
+	for _, obj := range model.RuntimeObjects {
+		_, ok := obj.(*BackendAuthAppConfig)
+		assert.False(t, ok)
+	}
+	for _, env := range model.backstageDeployment.container().Env {
+		assert.NotEqual(t, backendSecretEnvVar, env.Name)
+	}
+}
@@ -34,6 +34,7 @@ type ConfigMapFiles struct {
 	ConfigMap *corev1.ConfigMap
 	MountPath string
 	Key       string
+	MountMode v1alpha1.MountMode
 }
 
 func init() {
@@ -56,6 +57,7 @@ func addConfigMapFiles(spec v1alpha1.BackstageSpec, deployment *appsv1.Deploymen
 			ConfigMap: &cm,
 			MountPath: mp,
 			Key:       configMap.Key,
+			MountMode: configMap.MountMode,
 		}
 		cmf.updatePod(deployment)
 	}
@@ -100,7 +102,7 @@ func (p *ConfigMapFiles) setMetaInfo(backstageName string) {
 // implementation of BackstagePodContributor interface
 func (p *ConfigMapFiles) updatePod(deployment *appsv1.Deployment) {
 
-	utils.MountFilesFrom(&deployment.Spec.Template.Spec, &deployment.Spec.Template.Spec.Containers[0], utils.ConfigMapObjectKind,
-		p.ConfigMap.Name, p.MountPath, p.Key, p.ConfigMap.Data)
+	mountFiles(deployment, utils.ConfigMapObjectKind, p.ConfigMap.Name, p.MountPath, p.Key, p.ConfigMap.Data,
+		p.MountMode, extraFilesProjectedDir)
 
 }
@@ -19,6 +19,7 @@ import (
 
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"testing"
@@ -85,6 +86,9 @@ func TestSpecifiedConfigMapFiles(t *testing.T) {
 	*cmf = append(*cmf, bsv1alpha1.ObjectKeyRef{Name: appConfigTestCm2.Name})
 
 	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.ExtraFileConfigMaps = map[string]corev1.ConfigMap{}
+	testObj.externalConfig.ExtraFileConfigMaps[appConfigTestCm.Name] = appConfigTestCm
+	testObj.externalConfig.ExtraFileConfigMaps[appConfigTestCm2.Name] = appConfigTestCm2
 
 	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
 
@@ -94,7 +98,7 @@ func TestSpecifiedConfigMapFiles(t *testing.T) {
 	deployment := model.backstageDeployment
 	assert.NotNil(t, deployment)
 
-	assert.Equal(t, 2, len(deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts))
+	assert.Equal(t, 3, len(deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts))
 	assert.Equal(t, 0, len(deployment.deployment.Spec.Template.Spec.Containers[0].Args))
 	assert.Equal(t, 2, len(deployment.deployment.Spec.Template.Spec.Volumes))
 
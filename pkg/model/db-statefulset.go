@@ -24,6 +24,7 @@ import (
 	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -47,6 +48,12 @@ func DbStatefulSetName(backstageName string) string {
 	return utils.GenerateRuntimeObjectName(backstageName, "backstage-db")
 }
 
+// DbSelectorLabels returns the labels selecting local DB Pods,
+// the StatefulSet controller sets them on the PersistentVolumeClaims it creates as well
+func DbSelectorLabels(backstageName string) map[string]string {
+	return map[string]string{backstageAppLabel: fmt.Sprintf("backstage-db-%s", backstageName)}
+}
+
 // implementation of RuntimeObject interface
 func (b *DbStatefulSet) Object() client.Object {
 	return b.statefulSet
@@ -60,7 +67,7 @@ func (b *DbStatefulSet) setObject(obj client.Object) {
 }
 
 // implementation of RuntimeObject interface
-func (b *DbStatefulSet) addToModel(model *BackstageModel, _ bsv1alpha1.Backstage) (bool, error) {
+func (b *DbStatefulSet) addToModel(model *BackstageModel, backstage bsv1alpha1.Backstage) (bool, error) {
 	if b.statefulSet == nil {
 		if model.localDbEnabled {
 			return false, fmt.Errorf("LocalDb StatefulSet not configured, make sure there is db-statefulset.yaml.yaml in default or raw configuration")
@@ -81,6 +88,14 @@ func (b *DbStatefulSet) addToModel(model *BackstageModel, _ bsv1alpha1.Backstage
 		b.container().Image = os.Getenv(LocalDbImageEnvVar)
 	}
 
+	if backstage.Spec.Database != nil {
+		b.setStorage(backstage.Spec.Database.Storage)
+	}
+
+	if IsSuspended(backstage) {
+		b.statefulSet.Spec.Replicas = ptr.To(int32(0))
+	}
+
 	return true, nil
 }
 
@@ -97,6 +112,17 @@ func (b *DbStatefulSet) validate(model *BackstageModel, _ bsv1alpha1.Backstage)
 	return nil
 }
 
+// implementation of ReadinessGate interface
+// the local database is ready when the current generation is observed and all the replicas are ready
+func (b *DbStatefulSet) IsReady(live client.Object) bool {
+	sts, ok := live.(*appsv1.StatefulSet)
+	if !ok {
+		return false
+	}
+	return sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.ReadyReplicas >= ptr.Deref(sts.Spec.Replicas, 1)
+}
+
 func (b *DbStatefulSet) setMetaInfo(backstageName string) {
 	b.statefulSet.SetName(DbStatefulSetName(backstageName))
 	utils.GenerateLabel(&b.statefulSet.Spec.Template.ObjectMeta.Labels, backstageAppLabel, fmt.Sprintf("backstage-db-%s", backstageName))
@@ -118,3 +144,31 @@ func (b *DbStatefulSet) setDbEnvsFromSecret(name string) {
 		SecretRef: &corev1.SecretEnvSource{
 			LocalObjectReference: corev1.LocalObjectReference{Name: name}}})
 }
+
+// sets storage parameters of the DB volumeClaimTemplates (used by CR config)
+func (b *DbStatefulSet) setStorage(storage *bsv1alpha1.DbStorage) {
+	if storage == nil {
+		return
+	}
+	for i := range b.statefulSet.Spec.VolumeClaimTemplates {
+		pvcSpec := &b.statefulSet.Spec.VolumeClaimTemplates[i].Spec
+		if storage.Size != nil {
+			if pvcSpec.Resources.Requests == nil {
+				pvcSpec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvcSpec.Resources.Requests[corev1.ResourceStorage] = *storage.Size
+		}
+		if storage.StorageClassName != nil {
+			pvcSpec.StorageClassName = storage.StorageClassName
+		}
+		if len(storage.AccessModes) > 0 {
+			pvcSpec.AccessModes = storage.AccessModes
+		}
+	}
+	if storage.WhenDeleted != "" {
+		if b.statefulSet.Spec.PersistentVolumeClaimRetentionPolicy == nil {
+			b.statefulSet.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{}
+		}
+		b.statefulSet.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted = appsv1.PersistentVolumeClaimRetentionPolicyType(storage.WhenDeleted)
+	}
+}
@@ -19,6 +19,9 @@ import (
 	"os"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/ptr"
 
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
@@ -58,3 +61,27 @@ func TestOverrideDbImage(t *testing.T) {
 
 	assert.Equal(t, "dummy", model.localDbStatefulSet.statefulSet.Spec.Template.Spec.Containers[0].Image)
 }
+
+// It tests the local DB storage configured in the CR
+func TestDbStorage(t *testing.T) {
+	bs := *dbStatefulSetBackstage.DeepCopy()
+	bs.Spec.Database.Storage = &bsv1alpha1.DbStorage{
+		Size:             ptr.To(resource.MustParse("5Gi")),
+		StorageClassName: ptr.To("fast"),
+		AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
+		WhenDeleted:      "Delete",
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).withLocalDb()
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	sts := model.localDbStatefulSet.statefulSet
+	assert.Equal(t, 1, len(sts.Spec.VolumeClaimTemplates))
+	pvcSpec := sts.Spec.VolumeClaimTemplates[0].Spec
+	assert.Equal(t, "5Gi", ptr.To(pvcSpec.Resources.Requests[corev1.ResourceStorage]).String())
+	assert.Equal(t, "fast", *pvcSpec.StorageClassName)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}, pvcSpec.AccessModes)
+	assert.Equal(t, appsv1.DeletePersistentVolumeClaimRetentionPolicyType, sts.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted)
+}
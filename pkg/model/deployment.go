@@ -17,6 +17,7 @@ package model
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -25,10 +26,23 @@ import (
 	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// BackstageImageEnvVar overrides the image of the containers with backend and dynamic-plugins-installer roles,
+// unless the role specific env var is set
 const BackstageImageEnvVar = "RELATED_IMAGE_backstage"
+
+const (
+	// prefix of Backstage Pod template annotation (or label) defining the role of the container, for example
+	// container-role.rhdh.redhat.com/backstage-backend: backend
+	containerRolePrefix = "container-role.rhdh.redhat.com/"
+	// role of the container running Backstage, by default the first container
+	BackendContainerRole = "backend"
+	// role of the container installing dynamic plugins, by default install-dynamic-plugins init container
+	DynamicPluginsInstallerContainerRole = "dynamic-plugins-installer"
+)
 const defaultMountDir = "/opt/app-root/src"
 
 type BackstageDeploymentFactory struct{}
@@ -76,14 +90,11 @@ func (b *BackstageDeployment) addToModel(model *BackstageModel, _ bsv1alpha1.Bac
 
 	// override image with env var
 	// [GA] TODO Do we need this feature?
-	if os.Getenv(BackstageImageEnvVar) != "" {
-		b.deployment.Spec.Template.Spec.Containers[0].Image = os.Getenv(BackstageImageEnvVar)
-		// exactly the same image for initContainer and want it to be overriden
-		// the same way as Backstage's one
-		for i := range b.deployment.Spec.Template.Spec.InitContainers {
-			b.deployment.Spec.Template.Spec.InitContainers[i].Image = os.Getenv(BackstageImageEnvVar)
+	VisitContainers(b.podSpec(), func(container *corev1.Container) {
+		if image := containerImageFromEnv(b.containerRole(container)); image != "" {
+			container.Image = image
 		}
-	}
+	})
 
 	return true, nil
 }
@@ -94,10 +105,14 @@ func (b *BackstageDeployment) validate(model *BackstageModel, backstage bsv1alph
 	if backstage.Spec.Application != nil {
 		b.setReplicas(backstage.Spec.Application.Replicas)
 		b.setImagePullSecrets(backstage.Spec.Application.ImagePullSecrets)
-		b.setImage(backstage.Spec.Application.Image)
+		b.setImage(backstage.Spec.Application.Image, backstage.Spec.Application.Images)
 		b.addExtraEnvs(backstage.Spec.Application.ExtraEnvs)
 	}
 
+	if IsSuspended(backstage) {
+		b.deployment.Spec.Replicas = ptr.To(int32(0))
+	}
+
 	for _, bso := range model.RuntimeObjects {
 		if bs, ok := bso.(BackstagePodContributor); ok {
 			bs.updatePod(b.deployment)
@@ -127,7 +142,31 @@ func (b *BackstageDeployment) validate(model *BackstageModel, backstage bsv1alph
 			model.LocalDbSecret.secret.Name, "")
 	}
 
-	return nil
+	addTrustedCA(backstage.Spec, b, model)
+
+	addProxyEnvs(backstage.Spec, b.deployment, model)
+
+	// contributions of the extension modules, if any
+	if err := applyPodContributors(b.deployment, model, backstage); err != nil {
+		return err
+	}
+
+	// user volumes and containers are added as is, when the Pod is fully configured
+	if backstage.Spec.Application != nil {
+		if err := b.addExtraVolumes(backstage.Spec.Application.ExtraVolumes, backstage.Spec.Application.ExtraVolumeMounts); err != nil {
+			return err
+		}
+		if err := b.addExtraContainers(backstage.Spec.Application.ExtraContainers, backstage.Spec.Application.ExtraInitContainers); err != nil {
+			return err
+		}
+	}
+
+	if err := validateProjectedVolumes(b.podSpec(), model); err != nil {
+		return err
+	}
+
+	// volumes of the same source are shared, but the mount paths must not clash
+	return utils.ValidateVolumeMounts(b.podSpec())
 }
 
 func (b *BackstageDeployment) setMetaInfo(backstageName string) {
@@ -151,17 +190,51 @@ func (b *BackstageDeployment) setReplicas(replicas *int32) {
 	}
 }
 
-// sets container image name of Backstage Container
-func (b *BackstageDeployment) setImage(image *string) {
-	if image != nil {
-		// TODO this is a workaround for RHDH/Janus configuration
-		// it is not a fact that all the containers should be updated
-		// in general case need something smarter (probably annotation based)
-		// to mark/recognize containers for update
-		VisitContainers(b.podSpec(), func(container *corev1.Container) {
+// sets container images by the container role, containers with no role
+// (like spec.application.extraContainers, not added yet) are left alone
+func (b *BackstageDeployment) setImage(image *string, images map[string]string) {
+	VisitContainers(b.podSpec(), func(container *corev1.Container) {
+		role := b.containerRole(container)
+		if img, ok := images[role]; ok && role != "" {
+			container.Image = img
+		} else if image != nil && (role == BackendContainerRole || role == DynamicPluginsInstallerContainerRole) {
 			container.Image = *image
-		})
+		}
+	})
+}
+
+// containerRole returns the role of the container defined by the Pod template annotation or label,
+// Operator known containers have the role by default, empty if no role
+func (b *BackstageDeployment) containerRole(container *corev1.Container) string {
+	key := containerRolePrefix + container.Name
+	if role, ok := b.deployment.Spec.Template.Annotations[key]; ok {
+		return role
+	}
+	if role, ok := b.deployment.Spec.Template.Labels[key]; ok {
+		return role
+	}
+	if len(b.podSpec().Containers) > 0 && container.Name == b.podSpec().Containers[0].Name {
+		return BackendContainerRole
 	}
+	if container.Name == dynamicPluginInitContainerName {
+		return DynamicPluginsInstallerContainerRole
+	}
+	return ""
+}
+
+// containerImageFromEnv returns the image for the container role defined by RELATED_IMAGE_backstage_<role> env var
+// (dashes replaced with underscores), falls back to RELATED_IMAGE_backstage for the containers running Backstage image
+func containerImageFromEnv(role string) string {
+	if role == "" {
+		return ""
+	}
+	if image := os.Getenv(fmt.Sprintf("%s_%s", BackstageImageEnvVar, strings.ReplaceAll(role, "-", "_"))); image != "" {
+		return image
+	}
+	if role == BackendContainerRole || role == DynamicPluginsInstallerContainerRole {
+		return os.Getenv(BackstageImageEnvVar)
+	}
+	return ""
 }
 
 // adds environment variables to the Backstage Container
@@ -182,6 +255,78 @@ func (b *BackstageDeployment) addExtraEnvs(extraEnvs *bsv1alpha1.ExtraEnvs) {
 	}
 }
 
+// adds user supplied volumes to Backstage Pod and volume mounts to Backstage Container,
+// volume names must not collide with the volumes already defined in the Pod
+func (b *BackstageDeployment) addExtraVolumes(volumes []corev1.Volume, mounts []corev1.VolumeMount) error {
+	names := map[string]bool{}
+	for _, v := range b.podSpec().Volumes {
+		names[v.Name] = true
+	}
+	for _, v := range volumes {
+		if names[v.Name] {
+			return fmt.Errorf("extra volume name %s is already used in Backstage Pod", v.Name)
+		}
+		names[v.Name] = true
+		b.podSpec().Volumes = append(b.podSpec().Volumes, *v.DeepCopy())
+	}
+
+	paths := map[string]bool{}
+	for _, vm := range b.container().VolumeMounts {
+		paths[vm.MountPath] = true
+	}
+	for _, vm := range mounts {
+		if !names[vm.Name] {
+			return fmt.Errorf("extra volume mount %s refers to the volume not defined in Backstage Pod", vm.Name)
+		}
+		if paths[vm.MountPath] {
+			return fmt.Errorf("mount path %s of extra volume mount %s is already used in Backstage Container", vm.MountPath, vm.Name)
+		}
+		paths[vm.MountPath] = true
+		b.container().VolumeMounts = append(b.container().VolumeMounts, vm)
+	}
+	return nil
+}
+
+// adds user supplied sidecar and init containers to Backstage Pod,
+// names have to be unique and mounted volumes have to be defined in the Pod
+func (b *BackstageDeployment) addExtraContainers(containers []corev1.Container, initContainers []corev1.Container) error {
+	names := map[string]bool{}
+	VisitContainers(b.podSpec(), func(container *corev1.Container) {
+		names[container.Name] = true
+	})
+	volumes := map[string]bool{}
+	for _, v := range b.podSpec().Volumes {
+		volumes[v.Name] = true
+	}
+
+	check := func(c corev1.Container) error {
+		if names[c.Name] {
+			return fmt.Errorf("extra container name %s is already used in Backstage Pod", c.Name)
+		}
+		names[c.Name] = true
+		for _, vm := range c.VolumeMounts {
+			if !volumes[vm.Name] {
+				return fmt.Errorf("volume %s mounted to extra container %s is not defined in Backstage Pod", vm.Name, c.Name)
+			}
+		}
+		return nil
+	}
+
+	for _, c := range initContainers {
+		if err := check(c); err != nil {
+			return err
+		}
+		b.podSpec().InitContainers = append(b.podSpec().InitContainers, *c.DeepCopy())
+	}
+	for _, c := range containers {
+		if err := check(c); err != nil {
+			return err
+		}
+		b.podSpec().Containers = append(b.podSpec().Containers, *c.DeepCopy())
+	}
+	return nil
+}
+
 // sets pullSecret for Backstage Pod
 func (b *BackstageDeployment) setImagePullSecrets(pullSecrets []string) {
 	for _, ps := range pullSecrets {
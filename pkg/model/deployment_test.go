@@ -19,9 +19,11 @@ import (
 	"os"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
 
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -80,3 +82,211 @@ func TestOverrideBackstageImage(t *testing.T) {
 	assert.Equal(t, "dummy", model.backstageDeployment.container().Image)
 
 }
+
+func TestExtraContainers(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.Image = ptr.To("my-image:1.0.0")
+	bs.Spec.Application.ExtraContainers = []corev1.Container{
+		{
+			Name:  "oauth-proxy",
+			Image: "quay.io/oauth2-proxy/oauth2-proxy:latest",
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "dynamic-plugins-root", MountPath: "/plugins"},
+			},
+		},
+	}
+	bs.Spec.Application.ExtraInitContainers = []corev1.Container{
+		{Name: "init-cache", Image: "busybox"},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("deployment.yaml", "janus-deployment.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	podSpec := model.backstageDeployment.podSpec()
+	assert.Equal(t, 2, len(podSpec.Containers))
+	assert.Equal(t, "my-image:1.0.0", podSpec.Containers[0].Image)
+	// user containers are not overridden
+	assert.Equal(t, "oauth-proxy", podSpec.Containers[1].Name)
+	assert.Equal(t, "quay.io/oauth2-proxy/oauth2-proxy:latest", podSpec.Containers[1].Image)
+	last := podSpec.InitContainers[len(podSpec.InitContainers)-1]
+	assert.Equal(t, "init-cache", last.Name)
+	assert.Equal(t, "busybox", last.Image)
+
+	// only the containers with a role are managed
+	var managed []string
+	model.VisitManagedContainers(func(container *corev1.Container) {
+		managed = append(managed, container.Name)
+	})
+	assert.ElementsMatch(t, []string{podSpec.Containers[0].Name, "install-dynamic-plugins"}, managed)
+
+	// name collision
+	bs.Spec.Application.ExtraInitContainers[0].Name = "install-dynamic-plugins"
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "install-dynamic-plugins is already used")
+
+	// not existed volume
+	bs.Spec.Application.ExtraInitContainers = nil
+	bs.Spec.Application.ExtraContainers[0].VolumeMounts[0].Name = "not-existed"
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "volume not-existed mounted to extra container oauth-proxy is not defined")
+}
+
+func TestExtraVolumes(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.ExtraVolumes = []corev1.Volume{
+		{
+			Name: "techdocs-cache",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "my-pvc"},
+			},
+		},
+	}
+	bs.Spec.Application.ExtraVolumeMounts = []corev1.VolumeMount{
+		{Name: "techdocs-cache", MountPath: "/techdocs"},
+		// created by the Operator
+		{Name: "dynamic-plugins-root", MountPath: "/plugins"},
+	}
+	// sidecar can mount the extra volume
+	bs.Spec.Application.ExtraContainers = []corev1.Container{
+		{
+			Name:         "sidecar",
+			VolumeMounts: []corev1.VolumeMount{{Name: "techdocs-cache", MountPath: "/cache"}},
+		},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("deployment.yaml", "janus-deployment.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	podSpec := model.backstageDeployment.podSpec()
+	assert.Equal(t, "techdocs-cache", podSpec.Volumes[len(podSpec.Volumes)-1].Name)
+	mounts := model.backstageDeployment.container().VolumeMounts
+	assert.Equal(t, "/techdocs", mounts[len(mounts)-2].MountPath)
+	assert.Equal(t, "/plugins", mounts[len(mounts)-1].MountPath)
+
+	// name collision
+	bs.Spec.Application.ExtraVolumes[0].Name = "dynamic-plugins-npmrc"
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "extra volume name dynamic-plugins-npmrc is already used")
+
+	// not existed volume
+	bs.Spec.Application.ExtraVolumes = nil
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "extra volume mount techdocs-cache refers to the volume not defined")
+}
+
+func TestPodVolumesRegistry(t *testing.T) {
+
+	longName := "very-long-config-map-name-which-does-not-fit-into-the-volume-name.as.is"
+	shared := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns123"}, Data: map[string]string{"conf.yaml": ""}}
+	long := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: longName, Namespace: "ns123"}, Data: map[string]string{"long.yaml": ""}}
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.AppConfig = &bsv1alpha1.AppConfig{
+		MountPath:  "/app",
+		ConfigMaps: []bsv1alpha1.ObjectKeyRef{{Name: "shared"}},
+	}
+	bs.Spec.Application.ExtraFiles = &bsv1alpha1.ExtraFiles{
+		MountPath:  "/files",
+		ConfigMaps: []bsv1alpha1.ObjectKeyRef{{Name: "shared"}, {Name: longName}},
+		Secrets:    []bsv1alpha1.ObjectKeyRef{{Name: "shared", Key: "secret.yaml"}},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.AppConfigs = map[string]corev1.ConfigMap{"shared": shared}
+	testObj.externalConfig.ExtraFileConfigMaps = map[string]corev1.ConfigMap{"shared": shared, longName: long}
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	volumes := map[string]corev1.Volume{}
+	for _, v := range model.backstageDeployment.podSpec().Volumes {
+		assert.LessOrEqual(t, len(v.Name), 63)
+		volumes[v.Name] = v
+	}
+	mounts := map[string]string{}
+	for _, vm := range model.backstageDeployment.container().VolumeMounts {
+		mounts[vm.MountPath] = vm.Name
+	}
+
+	// the same ConfigMap mounted to both app-config and extra files directories through a single volume
+	assert.Contains(t, volumes, "cm-shared")
+	assert.Equal(t, "cm-shared", mounts["/app/conf.yaml"])
+	assert.Equal(t, "cm-shared", mounts["/files/conf.yaml"])
+	// Secret of the same name has its own volume
+	assert.NotNil(t, volumes["secret-shared"].Secret)
+	assert.Equal(t, "secret-shared", mounts["/files/secret.yaml"])
+	// too long name is truncated and made unique
+	longVol := mounts["/files/long.yaml"]
+	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, longName), longVol)
+	assert.Equal(t, longName, volumes[longVol].ConfigMap.Name)
+
+	// the same file mounted to the same path from different ConfigMaps
+	bs.Spec.Application.ExtraFiles.MountPath = "/app"
+	other := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns123"}, Data: map[string]string{"conf.yaml": ""}}
+	bs.Spec.Application.ExtraFiles.ConfigMaps = []bsv1alpha1.ObjectKeyRef{{Name: "other"}}
+	testObj.externalConfig.ExtraFileConfigMaps = map[string]corev1.ConfigMap{"other": other}
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "mount path /app/conf.yaml of container backstage-backend is used by both volume cm-shared and volume cm-other")
+}
+
+func TestContainerRoleImages(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.Image = ptr.To("my-image:1.0.0")
+	bs.Spec.Application.Images = map[string]string{
+		DynamicPluginsInstallerContainerRole: "my-installer:1.0.0",
+		"log-shipper":                        "my-shipper:1.0.0",
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["deployment.yaml"] = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bs
+spec:
+  template:
+    metadata:
+      annotations:
+        container-role.rhdh.redhat.com/shipper: log-shipper
+    spec:
+      initContainers:
+        - name: install-dynamic-plugins
+          image: quay.io/janus-idp/backstage-showcase:next
+        - name: other-init
+          image: busybox
+      containers:
+        - name: backstage-backend
+          image: quay.io/janus-idp/backstage-showcase:next
+        - name: shipper
+          image: shipper
+        - name: oauth-proxy
+          image: quay.io/oauth2-proxy/oauth2-proxy:latest
+`
+
+	t.Setenv(BackstageImageEnvVar+"_log_shipper", "env-shipper")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	podSpec := model.backstageDeployment.podSpec()
+	assert.Equal(t, "my-installer:1.0.0", podSpec.InitContainers[0].Image)
+	assert.Equal(t, "busybox", podSpec.InitContainers[1].Image)
+	assert.Equal(t, "my-image:1.0.0", podSpec.Containers[0].Image)
+	assert.Equal(t, "my-shipper:1.0.0", podSpec.Containers[1].Image)
+	assert.Equal(t, "quay.io/oauth2-proxy/oauth2-proxy:latest", podSpec.Containers[2].Image)
+
+	// env var by role
+	bs.Spec.Application.Images = nil
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "env-shipper", model.backstageDeployment.podSpec().Containers[1].Image)
+}
@@ -15,8 +15,8 @@
 package model
 
 import (
+	"bytes"
 	"fmt"
-	"os"
 
 	appsv1 "k8s.io/api/apps/v1"
 
@@ -25,6 +25,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+	goyaml "sigs.k8s.io/yaml/goyaml.v3"
 )
 
 const dynamicPluginInitContainerName = "install-dynamic-plugins"
@@ -121,18 +123,9 @@ func (p *DynamicPlugins) updatePod(deployment *appsv1.Deployment) {
 // ConfigMap name must be the same as (deployment.yaml).spec.template.spec.volumes.name.dynamic-plugins-conf.ConfigMap.name
 func (p *DynamicPlugins) validate(model *BackstageModel, _ v1alpha1.Backstage) error {
 
-	initContainer := dynamicPluginsInitContainer(model.backstageDeployment.deployment.Spec.Template.Spec.InitContainers)
-	if initContainer == nil {
+	if dynamicPluginsInitContainer(model.backstageDeployment.deployment.Spec.Template.Spec.InitContainers) == nil {
 		return fmt.Errorf("failed to find initContainer named %s", dynamicPluginInitContainerName)
 	}
-	// override image with env var
-	// [GA] Do we need this feature?
-	if os.Getenv(BackstageImageEnvVar) != "" {
-		// TODO workaround for the (janus-idp, rhdh) case where we have
-		// exactly the same image for initContainer and want it to be overriden
-		// the same way as Backstage's one
-		initContainer.Image = os.Getenv(BackstageImageEnvVar)
-	}
 	return nil
 }
 
@@ -150,3 +143,102 @@ func dynamicPluginsInitContainer(initContainers []corev1.Container) *corev1.Cont
 	}
 	return nil
 }
+
+// rewrites plugins[].package references of dynamic-plugins.yaml to the registry mirrors.
+// Only the package values are changed, so the comments and the order of keys are kept
+func (p *DynamicPlugins) rewritePackages(mirrors RegistryMirrors) error {
+	content, ok := p.ConfigMap.Data[DynamicPluginsFile]
+	if !ok {
+		return nil
+	}
+	doc := goyaml.Node{}
+	if err := goyaml.Unmarshal([]byte(content), &doc); err != nil {
+		return fmt.Errorf("failed to read %s, reason: %s", DynamicPluginsFile, err)
+	}
+	changed := false
+	for _, plugin := range yamlSequence(yamlMappingValue(yamlDocumentRoot(&doc), "plugins")) {
+		pkg := yamlMappingValue(plugin, "package")
+		if pkg == nil || pkg.Kind != goyaml.ScalarNode {
+			continue
+		}
+		if rewritten := mirrors.rewritePackage(pkg.Value); rewritten != pkg.Value {
+			pkg.Value = rewritten
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	out := bytes.Buffer{}
+	encoder := goyaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to write %s, reason: %s", DynamicPluginsFile, err)
+	}
+	p.ConfigMap.Data[DynamicPluginsFile] = out.String()
+	return nil
+}
+
+// returns the root node of yaml document, nil if empty
+func yamlDocumentRoot(doc *goyaml.Node) *goyaml.Node {
+	if doc.Kind != goyaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// returns the value node of the key of yaml mapping, nil if not a mapping or no such key
+func yamlMappingValue(node *goyaml.Node, key string) *goyaml.Node {
+	if node == nil || node.Kind != goyaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// returns the items of yaml sequence, nil if not a sequence
+func yamlSequence(node *goyaml.Node) []*goyaml.Node {
+	if node == nil || node.Kind != goyaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+// EnabledDynamicPlugins returns the number of the plugins listed in dynamic-plugins.yaml and not disabled,
+// the specified (Spec.Application.DynamicPluginsConfigMapName) configuration takes precedence over the default one.
+// Plugins of the included files are not counted
+func (m *BackstageModel) EnabledDynamicPlugins() (int, error) {
+	var cm *corev1.ConfigMap
+	if m.ExternalConfig.DynamicPlugins.Name != "" {
+		cm = &m.ExternalConfig.DynamicPlugins
+	} else {
+		for _, obj := range m.RuntimeObjects {
+			if dp, ok := obj.(*DynamicPlugins); ok {
+				cm = dp.ConfigMap
+			}
+		}
+	}
+	if cm == nil || cm.Data[DynamicPluginsFile] == "" {
+		return 0, nil
+	}
+
+	conf := struct {
+		Plugins []struct {
+			Disabled bool `json:"disabled,omitempty"`
+		} `json:"plugins,omitempty"`
+	}{}
+	if err := yaml.Unmarshal([]byte(cm.Data[DynamicPluginsFile]), &conf); err != nil {
+		return 0, fmt.Errorf("failed to read %s, reason: %s", DynamicPluginsFile, err)
+	}
+	enabled := 0
+	for _, plugin := range conf.Plugins {
+		if !plugin.Disabled {
+			enabled++
+		}
+	}
+	return enabled, nil
+}
@@ -105,7 +105,7 @@ func TestDefaultAndSpecifiedDynamicPlugins(t *testing.T) {
 	//dynamic-plugins-npmrc
 	//vol-dplugin
 	assert.Equal(t, 3, len(ic.VolumeMounts))
-	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret("dplugin"), ic.VolumeMounts[2].Name)
+	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret(utils.ConfigMapObjectKind, "dplugin"), ic.VolumeMounts[2].Name)
 	//t.Log(">>>>>>>>>>>>>>>>", ic.VolumeMounts)
 }
 
@@ -130,3 +130,38 @@ func initContainer(model *BackstageModel) *corev1.Container {
 	}
 	return nil
 }
+
+func TestEnabledDynamicPlugins(t *testing.T) {
+
+	bs := testDynamicPluginsBackstage.DeepCopy()
+
+	testObj := createBackstageTest(*bs).withDefaultConfig(true).
+		addToDefaultConfig("dynamic-plugins.yaml", "raw-dynamic-plugins.yaml").
+		addToDefaultConfig("deployment.yaml", "janus-deployment.yaml")
+
+	model, err := InitObjects(context.TODO(), *bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	enabled, err := model.EnabledDynamicPlugins()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, enabled)
+
+	// specified configuration
+	bs.Spec.Application.DynamicPluginsConfigMapName = "dplugin"
+	testObj.externalConfig.DynamicPlugins = corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "dplugin"},
+		Data: map[string]string{DynamicPluginsFile: `
+plugins:
+  - package: ./dynamic-plugins/dist/plugin-one
+    disabled: false
+  - package: ./dynamic-plugins/dist/plugin-two
+  - package: ./dynamic-plugins/dist/plugin-three
+    disabled: true
+`},
+	}
+
+	model, err = InitObjects(context.TODO(), *bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	enabled, err = model.EnabledDynamicPlugins()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, enabled)
+}
@@ -0,0 +1,205 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Extension API allowing the modules (of the custom Operators built on top of this one)
+// to add their own runtime objects and contributions to the Backstage Pod, for example OAuth proxy sidecar or CA injector.
+// The modules register their extensions in init() function, the same way the built-in objects do.
+
+// Registration defines the order of the registered object (or pod contributor) processing.
+type Registration struct {
+	// Order of processing in ascending order, built-in objects have 0.
+	// Registrations of the same order are processed in the order of registration
+	Order int
+	// After names of the registrations (configuration keys for the objects, like "deployment.yaml")
+	// which have to be processed before this one, regardless of the Order.
+	// Reference to not registered name is an error.
+	After []string
+}
+
+// ExtensionObject is the runtime object contributed by the extension module,
+// configured with the key of Operator default and BackstageCR.spec.rawRuntimeConfig configuration
+// and reconciled along with the built-in objects.
+type ExtensionObject interface {
+	// Object underlying Kubernetes object
+	Object() client.Object
+	// SetObject sets the object read from the configuration (can be nil)
+	SetObject(obj client.Object)
+	// EmptyObject an empty object the same kind as Object, the configuration is read into
+	EmptyObject() client.Object
+	// AddToModel applies Backstage spec to the object,
+	// returns false if the object was not added to the model (not configured)
+	AddToModel(model *BackstageModel, backstage bsv1alpha1.Backstage) (bool, error)
+	// Validate validates the object at the end of initialization, when all the objects are in the model
+	Validate(model *BackstageModel, backstage bsv1alpha1.Backstage) error
+	// SetMetaInfo sets object name, labels and other necessary meta information
+	SetMetaInfo(backstageName string)
+}
+
+// PodContributor contributes to the Backstage Pod (containers, volumes, env variables etc.),
+// after the built-in objects and before the extra volumes and containers defined in Backstage spec
+type PodContributor interface {
+	UpdatePod(deployment *appsv1.Deployment, model *BackstageModel, backstage bsv1alpha1.Backstage) error
+}
+
+// PodContributorFunc is a function implementing PodContributor
+type PodContributorFunc func(deployment *appsv1.Deployment, model *BackstageModel, backstage bsv1alpha1.Backstage) error
+
+func (f PodContributorFunc) UpdatePod(deployment *appsv1.Deployment, model *BackstageModel, backstage bsv1alpha1.Backstage) error {
+	return f(deployment, model, backstage)
+}
+
+type podContributorConfig struct {
+	name         string
+	contributor  PodContributor
+	registration Registration
+}
+
+var podContributors []podContributorConfig
+
+// RegisterObject registers the factory of the extension object configured with the key (like "oauth-proxy.yaml").
+// Panics if the key is already registered
+func RegisterObject(key string, factory func() ExtensionObject, registration Registration) {
+	for _, conf := range runtimeConfig {
+		if conf.Key == key {
+			panic(fmt.Sprintf("object with the key %s is already registered", key))
+		}
+	}
+	runtimeConfig = append(runtimeConfig, ObjectConfig{Key: key, ObjectFactory: extensionFactory(factory), Registration: registration})
+}
+
+// RegisterPodContributor registers the contributor to the Backstage Pod with the unique name.
+// Panics if the name is already registered
+func RegisterPodContributor(name string, contributor PodContributor, registration Registration) {
+	for _, conf := range podContributors {
+		if conf.name == name {
+			panic(fmt.Sprintf("pod contributor %s is already registered", name))
+		}
+	}
+	podContributors = append(podContributors, podContributorConfig{name: name, contributor: contributor, registration: registration})
+}
+
+// applies registered pod contributors ordered according to their Registration
+func applyPodContributors(deployment *appsv1.Deployment, model *BackstageModel, backstage bsv1alpha1.Backstage) error {
+	ordered, err := sortRegistrations(podContributors,
+		func(c podContributorConfig) (string, Registration) { return c.name, c.registration })
+	if err != nil {
+		return fmt.Errorf("failed to order pod contributors: %w", err)
+	}
+	for _, c := range ordered {
+		if err := c.contributor.UpdatePod(deployment, model, backstage); err != nil {
+			return fmt.Errorf("pod contributor %s failed: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// sortRegistrations orders the items by Registration.Order keeping the registration order for the equal ones,
+// so every item goes after the ones it depends on
+func sortRegistrations[T any](items []T, reg func(T) (string, Registration)) ([]T, error) {
+	index := map[string]int{}
+	for i, item := range items {
+		name, _ := reg(item)
+		index[name] = i
+	}
+	for _, item := range items {
+		name, r := reg(item)
+		for _, after := range r.After {
+			if _, ok := index[after]; !ok {
+				return nil, fmt.Errorf("%s depends on %s which is not registered", name, after)
+			}
+		}
+	}
+
+	done := make([]bool, len(items))
+	result := make([]T, 0, len(items))
+	for len(result) < len(items) {
+		next, order := -1, 0
+		for i, item := range items {
+			if done[i] {
+				continue
+			}
+			_, r := reg(item)
+			ready := true
+			for _, after := range r.After {
+				if !done[index[after]] {
+					ready = false
+					break
+				}
+			}
+			if ready && (next < 0 || r.Order < order) {
+				next, order = i, r.Order
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("circular dependency between %v", pending(items, done, reg))
+		}
+		done[next] = true
+		result = append(result, items[next])
+	}
+	return result, nil
+}
+
+func pending[T any](items []T, done []bool, reg func(T) (string, Registration)) []string {
+	var names []string
+	for i, item := range items {
+		if !done[i] {
+			name, _ := reg(item)
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// adapts ExtensionObject to the RuntimeObject
+type extensionFactory func() ExtensionObject
+
+func (f extensionFactory) newBackstageObject() RuntimeObject {
+	return &extensionObject{ExtensionObject: f()}
+}
+
+type extensionObject struct {
+	ExtensionObject
+}
+
+func (e *extensionObject) setObject(obj client.Object) {
+	e.SetObject(obj)
+}
+
+func (e *extensionObject) addToModel(model *BackstageModel, backstage bsv1alpha1.Backstage) (bool, error) {
+	added, err := e.AddToModel(model, backstage)
+	if err != nil || !added {
+		return false, err
+	}
+	model.RuntimeObjects = append(model.RuntimeObjects, e)
+	return true, nil
+}
+
+func (e *extensionObject) validate(model *BackstageModel, backstage bsv1alpha1.Backstage) error {
+	return e.Validate(model, backstage)
+}
+
+func (e *extensionObject) setMetaInfo(backstageName string) {
+	e.SetMetaInfo(backstageName)
+}
@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// CA bundle ConfigMap contributed by the extension module
+type testCaBundle struct {
+	configMap *corev1.ConfigMap
+}
+
+func (c *testCaBundle) Object() client.Object {
+	return c.configMap
+}
+
+func (c *testCaBundle) SetObject(obj client.Object) {
+	c.configMap = nil
+	if obj != nil {
+		c.configMap = obj.(*corev1.ConfigMap)
+	}
+}
+
+func (c *testCaBundle) EmptyObject() client.Object {
+	return &corev1.ConfigMap{}
+}
+
+func (c *testCaBundle) AddToModel(_ *BackstageModel, _ bsv1alpha1.Backstage) (bool, error) {
+	return c.configMap != nil, nil
+}
+
+func (c *testCaBundle) Validate(_ *BackstageModel, _ bsv1alpha1.Backstage) error {
+	return nil
+}
+
+func (c *testCaBundle) SetMetaInfo(backstageName string) {
+	c.configMap.SetName(utils.GenerateRuntimeObjectName(backstageName, "ca-bundle"))
+}
+
+// restores the registrations changed by the test
+func withRegistrations(t *testing.T) {
+	objects := runtimeConfig
+	contributors := podContributors
+	t.Cleanup(func() {
+		runtimeConfig = objects
+		podContributors = contributors
+	})
+}
+
+func TestExtensionObjectAndPodContributors(t *testing.T) {
+	withRegistrations(t)
+
+	var order []string
+	RegisterObject("ca-bundle.yaml", func() ExtensionObject { return &testCaBundle{} }, Registration{After: []string{"deployment.yaml"}})
+	// registered first, but goes after ca-injector
+	RegisterPodContributor("ca-env", PodContributorFunc(func(deployment *appsv1.Deployment, _ *BackstageModel, _ bsv1alpha1.Backstage) error {
+		order = append(order, "ca-env")
+		c := &deployment.Spec.Template.Spec.Containers[0]
+		c.Env = append(c.Env, corev1.EnvVar{Name: "NODE_EXTRA_CA_CERTS", Value: "/ca/ca-bundle.crt"})
+		return nil
+	}), Registration{After: []string{"ca-injector"}})
+	RegisterPodContributor("ca-injector", PodContributorFunc(func(deployment *appsv1.Deployment, model *BackstageModel, backstage bsv1alpha1.Backstage) error {
+		order = append(order, "ca-injector")
+		utils.MountDirFrom(&deployment.Spec.Template.Spec, &deployment.Spec.Template.Spec.Containers[0], utils.ConfigMapObjectKind,
+			utils.GenerateRuntimeObjectName(backstage.Name, "ca-bundle"), "/ca", "")
+		return nil
+	}), Registration{})
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["ca-bundle.yaml"] = `
+apiVersion: v1
+kind: ConfigMap
+data:
+  ca-bundle.crt: ""
+`
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	var caBundle client.Object
+	for _, obj := range model.RuntimeObjects {
+		if _, ok := obj.(*extensionObject); ok {
+			caBundle = obj.Object()
+		}
+	}
+	assert.NotNil(t, caBundle)
+	assert.Equal(t, "bs-ca-bundle", caBundle.GetName())
+	assert.Equal(t, "ns123", caBundle.GetNamespace())
+
+	assert.Equal(t, []string{"ca-injector", "ca-env"}, order)
+	container := model.backstageDeployment.container()
+	assert.Equal(t, "/ca", container.VolumeMounts[len(container.VolumeMounts)-1].MountPath)
+	assert.Equal(t, "NODE_EXTRA_CA_CERTS", container.Env[len(container.Env)-1].Name)
+
+	// not configured object is not added
+	delete(testObj.externalConfig.RawConfig, "ca-bundle.yaml")
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	for _, obj := range model.RuntimeObjects {
+		_, ok := obj.(*extensionObject)
+		assert.False(t, ok)
+	}
+
+	assert.Panics(t, func() {
+		RegisterObject("deployment.yaml", func() ExtensionObject { return &testCaBundle{} }, Registration{})
+	})
+}
+
+func TestPodContributorFailure(t *testing.T) {
+	withRegistrations(t)
+
+	RegisterPodContributor("failing", PodContributorFunc(func(_ *appsv1.Deployment, _ *BackstageModel, _ bsv1alpha1.Backstage) error {
+		return errors.New("no CA bundle")
+	}), Registration{})
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "pod contributor failing failed: no CA bundle")
+}
+
+func TestSortRegistrations(t *testing.T) {
+	type item struct {
+		name string
+		reg  Registration
+	}
+	reg := func(i item) (string, Registration) { return i.name, i.reg }
+	names := func(items []item) []string {
+		var res []string
+		for _, i := range items {
+			res = append(res, i.name)
+		}
+		return res
+	}
+
+	sorted, err := sortRegistrations([]item{
+		{name: "a"},
+		{name: "b", reg: Registration{Order: -1}},
+		{name: "c", reg: Registration{Order: -2, After: []string{"d"}}},
+		{name: "d", reg: Registration{Order: 1}},
+		{name: "e"},
+	}, reg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "a", "e", "d", "c"}, names(sorted))
+
+	_, err = sortRegistrations([]item{{name: "a", reg: Registration{After: []string{"x"}}}}, reg)
+	assert.EqualError(t, err, "a depends on x which is not registered")
+
+	_, err = sortRegistrations([]item{
+		{name: "a", reg: Registration{After: []string{"b"}}},
+		{name: "b", reg: Registration{After: []string{"a"}}},
+		{name: "c"},
+	}, reg)
+	assert.EqualError(t, err, "circular dependency between [a b]")
+}
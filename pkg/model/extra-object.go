@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// prefix of raw config key containing an additional object, for example extra-sa.yaml
+const extraObjectPrefix = "extra-"
+
+// ExtraObject is an arbitrary namespaced object defined in BackstageCR.Spec.RawRuntimeConfig ConfigMap
+// with extra-*.yaml key, it is applied as is (only namespace and labels are set) along with other runtime objects
+type ExtraObject struct {
+	key    string
+	object *unstructured.Unstructured
+	// to resolve the kind of the typed runtime objects the extra object must not collide with
+	scheme *runtime.Scheme
+}
+
+// Key of RawRuntimeConfig ConfigMap the object is defined in
+func (b *ExtraObject) Key() string {
+	return b.key
+}
+
+// implementation of RuntimeObject interface
+func (b *ExtraObject) Object() client.Object {
+	return b.object
+}
+
+func (b *ExtraObject) setObject(obj client.Object) {
+	b.object = nil
+	if obj != nil {
+		b.object = obj.(*unstructured.Unstructured)
+	}
+}
+
+// implementation of RuntimeObject interface
+func (b *ExtraObject) addToModel(model *BackstageModel, _ bsv1alpha1.Backstage) (bool, error) {
+	if b.object == nil {
+		return false, nil
+	}
+	// there can be many of ExtraObjects, so not model.setRuntimeObject
+	model.RuntimeObjects = append(model.RuntimeObjects, b)
+	return true, nil
+}
+
+// implementation of RuntimeObject interface
+func (b *ExtraObject) EmptyObject() client.Object {
+	obj := &unstructured.Unstructured{}
+	if b.object != nil {
+		obj.SetGroupVersionKind(b.object.GroupVersionKind())
+	}
+	return obj
+}
+
+// implementation of RuntimeObject interface
+func (b *ExtraObject) validate(model *BackstageModel, backstage bsv1alpha1.Backstage) error {
+	if b.object.GetAPIVersion() == "" || b.object.GetKind() == "" {
+		return fmt.Errorf("apiVersion and kind have to be defined for %s", b.key)
+	}
+	if b.object.GetName() == "" {
+		return fmt.Errorf("metadata.name has to be defined for %s", b.key)
+	}
+	groupKind := b.object.GroupVersionKind().GroupKind()
+	for _, obj := range model.RuntimeObjects {
+		if obj == b || obj.Object().GetName() != b.object.GetName() {
+			continue
+		}
+		if other, ok := obj.(*ExtraObject); ok {
+			if other.object.GroupVersionKind().GroupKind() == groupKind {
+				return fmt.Errorf("%s %s is defined in both %s and %s", b.object.GetKind(), b.object.GetName(), b.key, other.key)
+			}
+			continue
+		}
+		gvk, err := apiutil.GVKForObject(obj.Object(), b.scheme)
+		if err != nil {
+			return fmt.Errorf("failed to get kind of %s, reason: %s", obj.Object().GetName(), err)
+		}
+		if gvk.GroupKind() == groupKind {
+			return fmt.Errorf("%s %s defined in %s collides with the object managed by the Operator", b.object.GetKind(), b.object.GetName(), b.key)
+		}
+	}
+	return nil
+}
+
+// name is taken as is, so the object can be referenced by its name from other objects
+func (b *ExtraObject) setMetaInfo(backstageName string) {
+	// the object has to be recognized as owned by the instance
+	b.object.SetLabels(utils.SetKubeLabels(b.object.GetLabels(), backstageName))
+}
+
+// IsExtraObjectKey returns true if the key of RawRuntimeConfig ConfigMap defines an ExtraObject,
+// not a patch of it (extra-*.patch.yaml, extra-*.json-patch.yaml)
+func IsExtraObjectKey(key string) bool {
+	return strings.HasPrefix(key, extraObjectPrefix) && strings.HasSuffix(key, ".yaml") &&
+		!strings.HasSuffix(key, strategicMergePatchSuffix) && !strings.HasSuffix(key, jsonPatchSuffix)
+}
+
+// reads ExtraObjects defined in RawRuntimeConfig ConfigMap, sorted by the key.
+// Objects can be created only in the namespace of Backstage CR
+func readExtraObjects(rawConfig map[string]string, namespace string, scheme *runtime.Scheme) ([]*ExtraObject, error) {
+	keys := make([]string, 0)
+	for key := range rawConfig {
+		if IsExtraObjectKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	extras := make([]*ExtraObject, 0, len(keys))
+	for _, key := range keys {
+		obj := &unstructured.Unstructured{}
+		if err := utils.ReadYaml([]byte(rawConfig[key]), &obj.Object); err != nil {
+			return nil, fmt.Errorf("failed to read extra object for the key %s, reason: %s", key, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetNamespace() != "" && obj.GetNamespace() != namespace {
+			return nil, fmt.Errorf("extra object for the key %s can not be created in the namespace %s, only %s is allowed", key, obj.GetNamespace(), namespace)
+		}
+		extras = append(extras, &ExtraObject{key: key, object: obj, scheme: scheme})
+	}
+	return extras, nil
+}
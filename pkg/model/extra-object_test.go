@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtraObjects(t *testing.T) {
+
+	bs := *patchBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["extra-sa.yaml"] = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-sa
+`
+	testObj.externalConfig.RawConfig["extra-monitor.yaml"] = `
+apiVersion: monitoring.coreos.com/v1
+kind: PodMonitor
+metadata:
+  name: my-monitor
+  labels:
+    team: my-team
+spec:
+  podMetricsEndpoints:
+    - port: backend
+`
+	// not an extra object
+	testObj.externalConfig.RawConfig["my-sa.yaml"] = "kind: ServiceAccount"
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	extras := map[string]*ExtraObject{}
+	for _, obj := range model.RuntimeObjects {
+		if extra, ok := obj.(*ExtraObject); ok {
+			extras[extra.Key()] = extra
+		}
+	}
+	assert.Equal(t, 2, len(extras))
+	monitor := extras["extra-monitor.yaml"].Object()
+	assert.Equal(t, "my-monitor", monitor.GetName())
+	assert.Equal(t, "ns123", monitor.GetNamespace())
+	assert.Equal(t, "my-team", monitor.GetLabels()["team"])
+	assert.Equal(t, "bs", monitor.GetLabels()["app.kubernetes.io/instance"])
+	assert.Equal(t, 1, len(monitor.GetOwnerReferences()))
+	assert.Equal(t, "PodMonitor", extras["extra-monitor.yaml"].EmptyObject().GetObjectKind().GroupVersionKind().Kind)
+	assert.Equal(t, "my-sa", extras["extra-sa.yaml"].Object().GetName())
+}
+
+func TestInvalidExtraObjects(t *testing.T) {
+
+	bs := *patchBackstage.DeepCopy()
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["extra-sa.yaml"] = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-sa
+  namespace: other
+`
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "extra-sa.yaml can not be created in the namespace other")
+
+	testObj = createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["extra-sa.yaml"] = `
+apiVersion: v1
+kind: ServiceAccount
+`
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "metadata.name has to be defined for extra-sa.yaml")
+
+	testObj = createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["extra-sa.yaml"] = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-sa
+`
+	testObj.externalConfig.RawConfig["extra-sa2.yaml"] = testObj.externalConfig.RawConfig["extra-sa.yaml"]
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "ServiceAccount my-sa is defined in both")
+}
+
+func TestExtraObjectCollidesWithManagedObject(t *testing.T) {
+
+	bs := *patchBackstage.DeepCopy()
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["extra-deployment.yaml"] = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ` + DeploymentName(bs.Name) + `
+`
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "collides with the object managed by the Operator")
+
+	// same name, other kind
+	testObj = createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["extra-sa.yaml"] = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ` + DeploymentName(bs.Name) + `
+`
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+}
+
+func TestExtraObjectPatchKeys(t *testing.T) {
+	assert.True(t, IsExtraObjectKey("extra-sa.yaml"))
+	assert.False(t, IsExtraObjectKey("extra-sa.patch.yaml"))
+	assert.False(t, IsExtraObjectKey("extra-sa.json-patch.yaml"))
+	assert.False(t, IsExtraObjectKey("sa.yaml"))
+}
@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"path/filepath"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// OpenShift service CA generates the serving certificate to the Secret named with this annotation of the Service
+	servingCertAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	// directory the serving certificate Secret is mounted to
+	servingCertDir = "/opt/app-root/src/serving-cert"
+	// key of app-config configuring Backstage backend HTTPS
+	httpsAppConfigFile = "app-config.https.yaml"
+)
+
+type HTTPSAppConfigFactory struct{}
+
+func (f HTTPSAppConfigFactory) newBackstageObject() RuntimeObject {
+	return &HTTPSAppConfig{}
+}
+
+// HTTPSAppConfig is the app-config making Backstage backend serve HTTPS with the serving certificate generated by OpenShift,
+// used for reencrypt and passthrough Route termination
+type HTTPSAppConfig struct {
+	ConfigMap *corev1.ConfigMap
+	// name of the Secret containing the serving certificate
+	secretName string
+}
+
+func init() {
+	registerConfig("https-app-config.yaml", HTTPSAppConfigFactory{})
+}
+
+func HTTPSAppConfigName(backstageName string) string {
+	return utils.GenerateRuntimeObjectName(backstageName, "backstage-https-appconfig")
+}
+
+func ServingCertSecretName(backstageName string) string {
+	return utils.GenerateRuntimeObjectName(backstageName, "backstage-serving-cert")
+}
+
+// IsBackendTLSEnabled returns true if Backstage backend has to serve HTTPS, that is the case for OpenShift Route
+// terminated by (reencrypt) or passed through (passthrough) to the Backstage Pod
+func IsBackendTLSEnabled(spec bsv1alpha1.BackstageSpec, isOpenshift bool) bool {
+	return isOpenshift && spec.GetRouteTermination() != bsv1alpha1.TLSTerminationEdge
+}
+
+// implementation of RuntimeObject interface
+func (b *HTTPSAppConfig) Object() client.Object {
+	return b.ConfigMap
+}
+
+// implementation of RuntimeObject interface
+func (b *HTTPSAppConfig) setObject(obj client.Object) {
+	b.ConfigMap = nil
+	if obj != nil {
+		b.ConfigMap = obj.(*corev1.ConfigMap)
+	}
+}
+
+// implementation of RuntimeObject interface
+func (b *HTTPSAppConfig) EmptyObject() client.Object {
+	return &corev1.ConfigMap{}
+}
+
+// implementation of RuntimeObject interface
+func (b *HTTPSAppConfig) addToModel(model *BackstageModel, backstage bsv1alpha1.Backstage) (bool, error) {
+	if !IsBackendTLSEnabled(backstage.Spec, model.isOpenshift) {
+		return false, nil
+	}
+	// generated if not configured
+	if b.ConfigMap == nil || b.ConfigMap.Data[httpsAppConfigFile] == "" {
+		if b.ConfigMap == nil {
+			b.ConfigMap = &corev1.ConfigMap{}
+		}
+		b.ConfigMap.Data = map[string]string{httpsAppConfigFile: httpsAppConfig()}
+	}
+	model.setRuntimeObject(b)
+	return true, nil
+}
+
+// implementation of RuntimeObject interface
+func (b *HTTPSAppConfig) validate(model *BackstageModel, _ bsv1alpha1.Backstage) error {
+	metav1.SetMetaDataAnnotation(&model.backstageService.service.ObjectMeta, servingCertAnnotation, b.secretName)
+	return nil
+}
+
+func (b *HTTPSAppConfig) setMetaInfo(backstageName string) {
+	b.ConfigMap.SetName(HTTPSAppConfigName(backstageName))
+	b.secretName = ServingCertSecretName(backstageName)
+}
+
+// implementation of BackstagePodContributor interface
+// mounts the serving certificate and the app-config, and switches the probes of Backstage container to HTTPS
+func (b *HTTPSAppConfig) updatePod(deployment *appsv1.Deployment) {
+
+	podSpec := &deployment.Spec.Template.Spec
+	container := &deployment.Spec.Template.Spec.Containers[0]
+
+	utils.MountDirFrom(podSpec, container, utils.SecretObjectKind, b.secretName, servingCertDir, "")
+
+	utils.MountFilesFrom(podSpec, container, utils.ConfigMapObjectKind, b.ConfigMap.Name, defaultMountDir, httpsAppConfigFile, b.ConfigMap.Data)
+	container.Args = append(container.Args, "--config", filepath.Join(defaultMountDir, httpsAppConfigFile))
+
+	for _, probe := range []*corev1.Probe{container.ReadinessProbe, container.LivenessProbe, container.StartupProbe} {
+		if probe != nil && probe.HTTPGet != nil {
+			probe.HTTPGet.Scheme = corev1.URISchemeHTTPS
+		}
+	}
+}
+
+func httpsAppConfig() string {
+	return `backend:
+  https:
+    certificate:
+      cert:
+        $file: ` + filepath.Join(servingCertDir, corev1.TLSCertKey) + `
+      key:
+        $file: ` + filepath.Join(servingCertDir, corev1.TLSPrivateKeyKey) + `
+`
+}
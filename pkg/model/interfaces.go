@@ -28,6 +28,8 @@ type ObjectConfig struct {
 	// Unique key identifying the "kind" of Object which also is the name of config file.
 	// For example: "deployment.yaml" containing configuration of Backstage Deployment
 	Key string
+	// Order and dependencies of the object initialization
+	Registration Registration
 }
 
 // Interface for Runtime Objects factory method
@@ -26,6 +26,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
 )
@@ -50,6 +51,8 @@ func createBackstageTest(bs bsv1alpha1.Backstage) *testBackstageObject {
 		ExtraEnvConfigMaps:  map[string]corev1.ConfigMap{},
 	}
 	b := &testBackstageObject{backstage: bs, externalConfig: ec, scheme: runtime.NewScheme()}
+	// same as the Operator's scheme
+	utilruntime.Must(clientgoscheme.AddToScheme(b.scheme))
 	utilruntime.Must(bsv1alpha1.AddToScheme(b.scheme))
 	return b
 }
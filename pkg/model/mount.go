@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// directory (and projected volume name) the app-configs with Projected mount mode are merged into
+	appConfigProjectedDir = "app-config"
+	// directory (and projected volume name) the extra files with Projected mount mode are merged into
+	extraFilesProjectedDir = "extra-files"
+	// suffix of the projected volume name
+	projectedVolumeSuffix = "-projected"
+)
+
+// mountFiles mounts the files of ConfigMap or Secret to Backstage container according to the mount mode
+// and returns the directory the files are placed to
+func mountFiles(deployment *appsv1.Deployment, kind utils.ObjectKind, objectName, mountPath, key string, data map[string]string,
+	mode bsv1alpha1.MountMode, projectedDir string) string {
+
+	podSpec := &deployment.Spec.Template.Spec
+	container := &deployment.Spec.Template.Spec.Containers[0]
+
+	switch mode {
+	case bsv1alpha1.MountModeDirectory:
+		dir := filepath.Join(mountPath, objectName)
+		utils.MountDirFrom(podSpec, container, kind, objectName, dir, key)
+		return dir
+	case bsv1alpha1.MountModeProjected:
+		dir := filepath.Join(mountPath, projectedDir)
+		utils.AddProjectedSource(podSpec, container, projectedDir+projectedVolumeSuffix, dir, kind, objectName, key)
+		return dir
+	default:
+		utils.MountFilesFrom(podSpec, container, kind, objectName, mountPath, key, data)
+		return mountPath
+	}
+}
+
+// validateProjectedVolumes checks the volumes the objects with Projected mount mode are merged into:
+// all the objects of the volume have to be mounted to the same path and their file names have to be unique.
+// The files of the objects the Operator does not read (Secrets with no key specified) are not checked
+func validateProjectedVolumes(podSpec *corev1.PodSpec, model *BackstageModel) error {
+	for _, projectedDir := range []string{appConfigProjectedDir, extraFilesProjectedDir} {
+		volName := projectedDir + projectedVolumeSuffix
+		var volume *corev1.Volume
+		for i, v := range podSpec.Volumes {
+			if v.Name == volName && v.Projected != nil {
+				volume = &podSpec.Volumes[i]
+			}
+		}
+		if volume == nil {
+			continue
+		}
+
+		mountPath := ""
+		for _, vm := range podSpec.Containers[0].VolumeMounts {
+			if vm.Name != volName {
+				continue
+			}
+			if mountPath != "" && mountPath != vm.MountPath {
+				return fmt.Errorf("objects with Projected mount mode are merged into %s directory, so they must have the same mount path, found %s and %s",
+					projectedDir, mountPath, vm.MountPath)
+			}
+			mountPath = vm.MountPath
+		}
+
+		// file name -> object it is taken from
+		files := map[string]string{}
+		for _, src := range volume.Projected.Sources {
+			objectName, fileNames := projectedFiles(src, model)
+			for _, fileName := range fileNames {
+				if other, ok := files[fileName]; ok {
+					return fmt.Errorf("file %s is defined in both %s and %s, objects with Projected mount mode are merged into %s directory, so their file names must be unique",
+						fileName, other, objectName, projectedDir)
+				}
+				files[fileName] = objectName
+			}
+		}
+	}
+	return nil
+}
+
+// projectedFiles returns the name of the object of the projected volume source and its file names, if known
+func projectedFiles(src corev1.VolumeProjection, model *BackstageModel) (string, []string) {
+	var objectName string
+	var items []corev1.KeyToPath
+	var data map[string]string
+	switch {
+	case src.ConfigMap != nil:
+		objectName, items = src.ConfigMap.Name, src.ConfigMap.Items
+		if cm, ok := model.ExternalConfig.AppConfigs[objectName]; ok {
+			data = cm.Data
+		} else if cm, ok := model.ExternalConfig.ExtraFileConfigMaps[objectName]; ok {
+			data = cm.Data
+		}
+		objectName = fmt.Sprintf("ConfigMap %s", objectName)
+	case src.Secret != nil:
+		objectName, items = fmt.Sprintf("Secret %s", src.Secret.Name), src.Secret.Items
+	}
+
+	fileNames := make([]string, 0)
+	if len(items) > 0 {
+		for _, item := range items {
+			fileNames = append(fileNames, item.Path)
+		}
+		return objectName, fileNames
+	}
+	for fileName := range data {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	return objectName, fileNames
+}
@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// suffix of raw config key containing strategic merge patch, for example deployment.patch.yaml
+	strategicMergePatchSuffix = ".patch.yaml"
+	// suffix of raw config key containing JSON6902 patch, for example deployment.json-patch.yaml
+	jsonPatchSuffix = ".json-patch.yaml"
+)
+
+// applyPatches patches the object configured for the key (like deployment.yaml) with
+// strategic merge (deployment.patch.yaml) and then JSON6902 (deployment.json-patch.yaml) patches
+// if defined in BackstageCR.Spec.RawRuntimeConfig ConfigMap
+func applyPatches(rawConfig map[string]string, key string, backstageObject RuntimeObject) error {
+
+	name := strings.TrimSuffix(key, filepath.Ext(key))
+	smpKey := name + strategicMergePatchSuffix
+	jpKey := name + jsonPatchSuffix
+	smp, smpExist := rawConfig[smpKey]
+	jp, jpExist := rawConfig[jpKey]
+	if !smpExist && !jpExist {
+		return nil
+	}
+
+	// Object() is a typed nil pointer if neither default nor raw configuration is defined
+	if obj := backstageObject.Object(); obj == nil || (reflect.ValueOf(obj).Kind() == reflect.Ptr && reflect.ValueOf(obj).IsNil()) {
+		return fmt.Errorf("failed to apply patch for the key %s, reason: no default or raw configuration to patch", key)
+	}
+
+	patched, err := json.Marshal(backstageObject.Object())
+	if err != nil {
+		return fmt.Errorf("failed to marshal object for the key %s, reason: %s", key, err)
+	}
+
+	if smpExist {
+		patch, err := yaml.YAMLToJSON([]byte(smp))
+		if err != nil {
+			return fmt.Errorf("failed to read patch %s, reason: %s", smpKey, err)
+		}
+		if patched, err = strategicpatch.StrategicMergePatch(patched, patch, backstageObject.EmptyObject()); err != nil {
+			return fmt.Errorf("failed to apply patch %s, reason: %s", smpKey, err)
+		}
+	}
+
+	if jpExist {
+		patchJson, err := yaml.YAMLToJSON([]byte(jp))
+		if err != nil {
+			return fmt.Errorf("failed to read patch %s, reason: %s", jpKey, err)
+		}
+		patch, err := jsonpatch.DecodePatch(patchJson)
+		if err != nil {
+			return fmt.Errorf("failed to read patch %s, reason: %s", jpKey, err)
+		}
+		if patched, err = patch.Apply(patched); err != nil {
+			return fmt.Errorf("failed to apply patch %s, reason: %s", jpKey, err)
+		}
+	}
+
+	obj := backstageObject.EmptyObject()
+	if err := json.Unmarshal(patched, obj); err != nil {
+		return fmt.Errorf("failed to unmarshal patched object for the key %s, reason: %s", key, err)
+	}
+	backstageObject.setObject(obj)
+
+	return nil
+}
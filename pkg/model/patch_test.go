@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var patchBackstage = &bsv1alpha1.Backstage{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "bs",
+		Namespace: "ns123",
+	},
+	Spec: bsv1alpha1.BackstageSpec{
+		Database: &bsv1alpha1.Database{
+			EnableLocalDb: ptr.To(false),
+		},
+	},
+}
+
+func TestStrategicMergePatch(t *testing.T) {
+
+	bs := *patchBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["deployment.patch.yaml"] = `
+spec:
+  template:
+    spec:
+      containers:
+        - name: backstage-backend
+          env:
+            - name: MY_ENV
+              value: my-value
+        - name: sidecar
+          image: busybox
+`
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	containers := model.backstageDeployment.deployment.Spec.Template.Spec.Containers
+	assert.Equal(t, 2, len(containers))
+	// merged with the default container by name
+	assert.Equal(t, "backstage-backend", containers[0].Name)
+	assert.Equal(t, 1, len(containers[0].Ports))
+	assert.Equal(t, "MY_ENV", containers[0].Env[0].Name)
+	assert.Equal(t, "sidecar", containers[1].Name)
+}
+
+func TestJsonPatch(t *testing.T) {
+
+	bs := *patchBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["deployment.json-patch.yaml"] = `
+- op: replace
+  path: /spec/replicas
+  value: 3
+- op: add
+  path: /spec/template/spec/containers/0/args
+  value: ["--debug"]
+`
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(3), *model.backstageDeployment.deployment.Spec.Replicas)
+	assert.Equal(t, []string{"--debug"}, model.backstageDeployment.container().Args)
+	assert.Equal(t, "backstage-backend", model.backstageDeployment.container().Name)
+}
+
+func TestInvalidPatch(t *testing.T) {
+
+	bs := *patchBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["service.json-patch.yaml"] = `
+- op: remove
+  path: /spec/notExisted
+`
+
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "service.json-patch.yaml")
+
+	// nothing to patch
+	testObj = createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["route.patch.yaml"] = `
+spec:
+  host: my.host
+`
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "route.yaml")
+}
@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ProxyConfig HTTP(S) proxy configuration propagated to the Backstage Pod containers
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// ProxyConfigFromEnv reads the proxy configuration from the Operator's env variables,
+// upper case variables take precedence over lower case ones
+func ProxyConfigFromEnv() ProxyConfig {
+	env := func(name string) string {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		return os.Getenv(strings.ToLower(name))
+	}
+	return ProxyConfig{
+		HTTPProxy:  env("HTTP_PROXY"),
+		HTTPSProxy: env("HTTPS_PROXY"),
+		NoProxy:    env("NO_PROXY"),
+	}
+}
+
+// IsEmpty returns true if no proxy is configured
+func (p ProxyConfig) IsEmpty() bool {
+	return p.HTTPProxy == "" && p.HTTPSProxy == ""
+}
+
+// addProxyEnvs sets the proxy env variables to the containers of Backstage Pod configured by the Operator,
+// the values of Backstage spec take precedence over the cluster (Operator) ones.
+// The variables already defined in the container are left as is
+func addProxyEnvs(spec bsv1alpha1.BackstageSpec, deployment *appsv1.Deployment, model *BackstageModel) {
+
+	proxy := model.ExternalConfig.Proxy
+	if spec.Application != nil && spec.Application.Proxy != nil {
+		specified := spec.Application.Proxy
+		if !ptr.Deref(specified.Enabled, true) {
+			return
+		}
+		if specified.HTTPProxy != "" {
+			proxy.HTTPProxy = specified.HTTPProxy
+		}
+		if specified.HTTPSProxy != "" {
+			proxy.HTTPSProxy = specified.HTTPSProxy
+		}
+		if specified.NoProxy != "" {
+			proxy.NoProxy = specified.NoProxy
+		}
+	}
+	if proxy.IsEmpty() {
+		return
+	}
+
+	envs := []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+		{Name: "NO_PROXY", Value: noProxy(proxy.NoProxy, model)},
+	}
+
+	VisitContainers(&deployment.Spec.Template.Spec, func(container *corev1.Container) {
+		for _, env := range envs {
+			if env.Value != "" && !hasEnvVar(container, env.Name) {
+				container.Env = append(container.Env, env)
+			}
+		}
+	})
+}
+
+// noProxy appends the hosts of Backstage and local database services to the noProxy list
+func noProxy(noProxy string, model *BackstageModel) string {
+	var hosts []string
+	if noProxy != "" {
+		hosts = append(hosts, noProxy)
+	}
+	services := []*corev1.Service{model.backstageService.service}
+	if model.LocalDbService != nil {
+		services = append(services, model.LocalDbService.service)
+	}
+	for _, svc := range services {
+		hosts = append(hosts, svc.Name,
+			fmt.Sprintf("%s.%s", svc.Name, svc.Namespace),
+			fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace))
+	}
+	return strings.Join(hosts, ",")
+}
+
+func hasEnvVar(container *corev1.Container, name string) bool {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
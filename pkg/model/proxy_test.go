@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func envValue(container corev1.Container, name string) (string, bool) {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestProxyEnvs(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Database.EnableLocalDb = ptr.To(true)
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("deployment.yaml", "janus-deployment.yaml").
+		addToDefaultConfig("db-statefulset.yaml", "janus-db-statefulset.yaml")
+	testObj.externalConfig.Proxy = ProxyConfig{
+		HTTPProxy:  "http://proxy:3128",
+		HTTPSProxy: "http://proxy:3129",
+		NoProxy:    ".cluster.local",
+	}
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	podSpec := model.backstageDeployment.podSpec()
+	for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+		value, _ := envValue(c, "HTTP_PROXY")
+		assert.Equal(t, "http://proxy:3128", value)
+		value, _ = envValue(c, "HTTPS_PROXY")
+		assert.Equal(t, "http://proxy:3129", value)
+		value, _ = envValue(c, "NO_PROXY")
+		assert.Equal(t, ".cluster.local,"+
+			"bs-backstage,bs-backstage.ns123,bs-backstage.ns123.svc,"+
+			"bs-backstage-db,bs-backstage-db.ns123,bs-backstage-db.ns123.svc", value)
+	}
+
+	// Backstage spec takes precedence
+	bs.Spec.Application.Proxy = &bsv1alpha1.Proxy{HTTPSProxy: "http://my-proxy:3129", NoProxy: "example.com"}
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	container := *model.backstageDeployment.container()
+	value, _ := envValue(container, "HTTP_PROXY")
+	assert.Equal(t, "http://proxy:3128", value)
+	value, _ = envValue(container, "HTTPS_PROXY")
+	assert.Equal(t, "http://my-proxy:3129", value)
+	value, _ = envValue(container, "NO_PROXY")
+	assert.Contains(t, value, "example.com,bs-backstage,")
+
+	// disabled
+	bs.Spec.Application.Proxy.Enabled = ptr.To(false)
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	_, ok := envValue(*model.backstageDeployment.container(), "HTTPS_PROXY")
+	assert.False(t, ok)
+}
+
+func TestNoProxyEnvs(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	_, ok := envValue(*model.backstageDeployment.container(), "NO_PROXY")
+	assert.False(t, ok)
+}
+
+func TestProxyConfigFromEnv(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "http://proxy:3128")
+	t.Setenv("HTTPS_PROXY", "http://proxy:3129")
+	t.Setenv("https_proxy", "http://other:3129")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+
+	assert.Equal(t, ProxyConfig{HTTPProxy: "http://proxy:3128", HTTPSProxy: "http://proxy:3129"}, ProxyConfigFromEnv())
+}
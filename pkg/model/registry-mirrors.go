@@ -0,0 +1,150 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// default configuration key containing registry mirrors as a map of source prefix to mirror prefix, for example:
+// quay.io/janus-idp: mirror.example.com/janus-idp
+const registryMirrorsKey = "registry-mirrors.yaml"
+
+const ociPackagePrefix = "oci://"
+
+// npm registry plain npm package names (like @janus-idp/plugin@1.0.0) of dynamic plugins are installed from
+// unless mirrored with npmRegistryEnvVar of the dynamic plugins installer
+const npmDefaultRegistry = "https://registry.npmjs.org/"
+
+const npmRegistryEnvVar = "NPM_CONFIG_REGISTRY"
+
+// RegistryMirrors maps the prefixes of container image references and dynamic plugin package references
+// (like registry.example.com/org or https://registry.npmjs.org) to the prefixes of their mirrors
+type RegistryMirrors map[string]string
+
+// ParseRegistryMirrors parses comma separated list of source=mirror pairs
+func ParseRegistryMirrors(value string) (RegistryMirrors, error) {
+	mirrors := RegistryMirrors{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		source, mirror, ok := strings.Cut(pair, "=")
+		if !ok || source == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q, expected source=mirror", pair)
+		}
+		mirrors[source] = mirror
+	}
+	return mirrors, nil
+}
+
+// rewrite replaces the longest matching source prefix of the reference with its mirror.
+// The prefix has to match whole path segments, so quay.io does not match quay.io.example.com/image
+func (m RegistryMirrors) rewrite(ref string) string {
+	match := ""
+	for source := range m {
+		if len(source) > len(match) && strings.HasPrefix(ref, source) &&
+			(len(ref) == len(source) || strings.HasSuffix(source, "/") || strings.ContainsAny(ref[len(source):len(source)+1], "/:@")) {
+			match = source
+		}
+	}
+	if match == "" {
+		return ref
+	}
+	return m[match] + ref[len(match):]
+}
+
+// rewritePackage rewrites dynamic plugin package reference, for OCI packages (oci://<image>!<path>)
+// the image reference is rewritten, other (URL) references are rewritten as is.
+// Plain npm package names are not references to rewrite, see applyRegistryMirrors
+func (m RegistryMirrors) rewritePackage(pkg string) string {
+	if strings.HasPrefix(pkg, ociPackagePrefix) {
+		return ociPackagePrefix + m.rewrite(strings.TrimPrefix(pkg, ociPackagePrefix))
+	}
+	return m.rewrite(pkg)
+}
+
+// reads registry mirrors from the default configuration, if configured
+func readRegistryMirrors(defaultConfig map[string]string, profile string) (RegistryMirrors, error) {
+	content, err := readDefaultContent(defaultConfig, profile, registryMirrorsKey)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	mirrors := RegistryMirrors{}
+	if err := yaml.Unmarshal(content, &mirrors); err != nil {
+		return nil, fmt.Errorf("failed to read %s, reason: %s", registryMirrorsKey, err)
+	}
+	return mirrors, nil
+}
+
+// applyRegistryMirrors rewrites the images of all the containers of Backstage and local DB Pods
+// and the packages of Operator generated dynamic plugins configuration,
+// the one specified with Spec.Application.DynamicPluginsConfigMapName is user owned, so not rewritten.
+// If the default npm registry is mirrored, the dynamic plugins installer is configured to use the mirror,
+// so plain npm package names are installed from it in both cases.
+// Mirrors of the default configuration take precedence over the Operator level ones
+func (m *BackstageModel) applyRegistryMirrors(profile string) error {
+	mirrors := RegistryMirrors{}
+	for source, mirror := range m.ExternalConfig.RegistryMirrors {
+		mirrors[source] = mirror
+	}
+	configured, err := readRegistryMirrors(m.ExternalConfig.DefaultConfig, profile)
+	if err != nil {
+		return err
+	}
+	for source, mirror := range configured {
+		mirrors[source] = mirror
+	}
+	if len(mirrors) == 0 {
+		return nil
+	}
+
+	rewriteImage := func(container *corev1.Container) {
+		container.Image = mirrors.rewrite(container.Image)
+	}
+	if b := m.backstageDeployment; b != nil {
+		VisitContainers(b.podSpec(), rewriteImage)
+
+		if npmRegistry := mirrors.rewrite(npmDefaultRegistry); npmRegistry != npmDefaultRegistry {
+			VisitContainers(b.podSpec(), func(container *corev1.Container) {
+				if b.containerRole(container) == DynamicPluginsInstallerContainerRole && !hasEnvVar(container, npmRegistryEnvVar) {
+					container.Env = append(container.Env, corev1.EnvVar{Name: npmRegistryEnvVar, Value: npmRegistry})
+				}
+			})
+		}
+	}
+	if m.localDbStatefulSet != nil {
+		VisitContainers(&m.localDbStatefulSet.statefulSet.Spec.Template.Spec, rewriteImage)
+	}
+
+	for _, obj := range m.RuntimeObjects {
+		if dp, ok := obj.(*DynamicPlugins); ok {
+			if err := dp.rewritePackages(mirrors); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRegistryMirrors(t *testing.T) {
+
+	mirrors, err := ParseRegistryMirrors("quay.io=mirror.local/quay, docker.io/library=mirror.local/docker")
+	assert.NoError(t, err)
+	assert.Equal(t, RegistryMirrors{"quay.io": "mirror.local/quay", "docker.io/library": "mirror.local/docker"}, mirrors)
+
+	mirrors, err = ParseRegistryMirrors("")
+	assert.NoError(t, err)
+	assert.Empty(t, mirrors)
+
+	_, err = ParseRegistryMirrors("quay.io")
+	assert.ErrorContains(t, err, "invalid registry mirror")
+}
+
+func TestRegistryMirrorsRewrite(t *testing.T) {
+
+	mirrors := RegistryMirrors{
+		"quay.io":                     "mirror.local/quay",
+		"quay.io/janus-idp":           "mirror.local/janus",
+		"https://registry.npmjs.org/": "https://npm.mirror.local/",
+	}
+
+	assert.Equal(t, "mirror.local/janus/backstage-showcase:next", mirrors.rewrite("quay.io/janus-idp/backstage-showcase:next"))
+	assert.Equal(t, "mirror.local/quay/fedora/postgresql-15:latest", mirrors.rewrite("quay.io/fedora/postgresql-15:latest"))
+	// whole path segments only
+	assert.Equal(t, "quay.io.example.com/image", mirrors.rewrite("quay.io.example.com/image"))
+	assert.Equal(t, "ghcr.io/backstage/backstage", mirrors.rewrite("ghcr.io/backstage/backstage"))
+
+	assert.Equal(t, "oci://mirror.local/janus/plugins:1.0!my-plugin", mirrors.rewritePackage("oci://quay.io/janus-idp/plugins:1.0!my-plugin"))
+	assert.Equal(t, "https://npm.mirror.local/my-plugin/-/my-plugin-1.0.0.tgz", mirrors.rewritePackage("https://registry.npmjs.org/my-plugin/-/my-plugin-1.0.0.tgz"))
+	assert.Equal(t, "./dynamic-plugins/dist/my-plugin", mirrors.rewritePackage("./dynamic-plugins/dist/my-plugin"))
+}
+
+func TestApplyRegistryMirrors(t *testing.T) {
+
+	t.Setenv(BackstageImageEnvVar, "")
+	t.Setenv(LocalDbImageEnvVar, "")
+
+	bs := bsv1alpha1.Backstage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bs",
+			Namespace: "ns123",
+		},
+		Spec: bsv1alpha1.BackstageSpec{
+			Database: &bsv1alpha1.Database{
+				EnableLocalDb: ptr.To(false),
+			},
+			Application: &bsv1alpha1.Application{
+				ExtraContainers: []corev1.Container{{Name: "sidecar", Image: "docker.io/library/busybox"}},
+			},
+		},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).withLocalDb().
+		addToDefaultConfig("deployment.yaml", "janus-deployment.yaml").
+		addToDefaultConfig("dynamic-plugins.yaml", "raw-dynamic-plugins.yaml")
+	testObj.externalConfig.RawConfig["dynamic-plugins.patch.yaml"] = `
+data:
+  dynamic-plugins.yaml: |
+    plugins:
+      # my OCI plugin
+      - package: oci://quay.io/janus-idp/plugins:1.0!my-plugin
+        disabled: false
+      - package: ./dynamic-plugins/dist/my-plugin
+      - package: "@janus-idp/my-npm-plugin@1.0.0"
+`
+	testObj.externalConfig.RegistryMirrors = RegistryMirrors{"quay.io": "mirror.local/quay", "docker.io": "mirror.local/docker",
+		"https://registry.npmjs.org": "https://npm.mirror.local"}
+
+	model, err := InitObjects(context.TODO(), testObj.backstage, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	podSpec := model.backstageDeployment.podSpec()
+	assert.Equal(t, "mirror.local/quay/janus-idp/backstage-showcase:next", podSpec.Containers[0].Image)
+	assert.Equal(t, "mirror.local/quay/janus-idp/backstage-showcase:next", podSpec.InitContainers[0].Image)
+	assert.Equal(t, "mirror.local/docker/library/busybox", podSpec.Containers[1].Image)
+	assert.Equal(t, "mirror.local/quay/fedora/postgresql-15:latest", model.localDbStatefulSet.container().Image)
+	// plain npm package names are installed from the npm registry mirror
+	assert.Contains(t, podSpec.InitContainers[0].Env, corev1.EnvVar{Name: "NPM_CONFIG_REGISTRY", Value: "https://npm.mirror.local/"})
+	assert.False(t, hasEnvVar(&podSpec.Containers[0], "NPM_CONFIG_REGISTRY"))
+
+	var dp *DynamicPlugins
+	for _, obj := range model.RuntimeObjects {
+		if d, ok := obj.(*DynamicPlugins); ok {
+			dp = d
+		}
+	}
+	assert.NotNil(t, dp)
+	assert.Contains(t, dp.ConfigMap.Data[DynamicPluginsFile], "package: oci://mirror.local/quay/janus-idp/plugins:1.0!my-plugin")
+	assert.Contains(t, dp.ConfigMap.Data[DynamicPluginsFile], "package: ./dynamic-plugins/dist/my-plugin")
+	assert.Contains(t, dp.ConfigMap.Data[DynamicPluginsFile], `package: "@janus-idp/my-npm-plugin@1.0.0"`)
+	// only the packages are changed
+	assert.Contains(t, dp.ConfigMap.Data[DynamicPluginsFile], "# my OCI plugin")
+}
+
+func TestRegistryMirrorsFromDefaultConfig(t *testing.T) {
+
+	t.Setenv(BackstageImageEnvVar, "")
+
+	bs := *patchBackstage.DeepCopy()
+	testObj := createBackstageTest(bs)
+
+	deployment, err := readTestYamlFile("default-config/deployment.yaml")
+	assert.NoError(t, err)
+	service, err := readTestYamlFile("default-config/service.yaml")
+	assert.NoError(t, err)
+	testObj.externalConfig.DefaultConfig = map[string]string{
+		"deployment.yaml": string(deployment),
+		"service.yaml":    string(service),
+		"registry-mirrors.yaml": `
+ghcr.io/backstage: mirror.local/backstage
+`,
+	}
+	// default configuration takes precedence
+	testObj.externalConfig.RegistryMirrors = RegistryMirrors{"ghcr.io/backstage": "other.local/backstage"}
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, "mirror.local/backstage/backstage", model.backstageDeployment.container().Image)
+}
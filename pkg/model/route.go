@@ -15,6 +15,8 @@
 package model
 
 import (
+	"fmt"
+
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
 	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
 
@@ -51,7 +53,7 @@ func (b *BackstageRoute) setRoute(specified bsv1alpha1.Route) {
 	}
 	if b.route.Spec.TLS == nil {
 		b.route.Spec.TLS = &openshift.TLSConfig{
-			Termination:                   openshift.TLSTerminationEdge,
+			Termination:                   routeTermination(specified.TLS.Termination),
 			InsecureEdgeTerminationPolicy: openshift.InsecureEdgeTerminationPolicyRedirect,
 			Certificate:                   specified.TLS.Certificate,
 			Key:                           specified.TLS.Key,
@@ -79,6 +81,20 @@ func (b *BackstageRoute) setRoute(specified bsv1alpha1.Route) {
 			Name: specified.TLS.ExternalCertificateSecretName,
 		}
 	}
+	if len(specified.TLS.Termination) > 0 {
+		b.route.Spec.TLS.Termination = routeTermination(specified.TLS.Termination)
+	}
+}
+
+func routeTermination(termination bsv1alpha1.TLSTermination) openshift.TLSTerminationType {
+	switch termination {
+	case bsv1alpha1.TLSTerminationReencrypt:
+		return openshift.TLSTerminationReencrypt
+	case bsv1alpha1.TLSTerminationPassthrough:
+		return openshift.TLSTerminationPassthrough
+	default:
+		return openshift.TLSTerminationEdge
+	}
 }
 
 func init() {
@@ -123,6 +139,12 @@ func (b *BackstageRoute) addToModel(model *BackstageModel, backstage bsv1alpha1.
 // implementation of RuntimeObject interface
 func (b *BackstageRoute) validate(model *BackstageModel, _ bsv1alpha1.Backstage) error {
 	b.route.Spec.To.Name = model.backstageService.service.Name
+	// the router does not terminate passthrough TLS, OpenShift rejects the Route with certificates
+	if tls := b.route.Spec.TLS; tls != nil && tls.Termination == openshift.TLSTerminationPassthrough &&
+		(tls.Certificate != "" || tls.Key != "" || tls.CACertificate != "" || tls.DestinationCACertificate != "" ||
+			(tls.ExternalCertificate != nil && tls.ExternalCertificate.Name != "")) {
+		return fmt.Errorf("failed to configure Route %s, reason: certificates can not be set with passthrough TLS termination", b.route.Name)
+	}
 	return nil
 }
 
@@ -23,6 +23,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	openshift "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -100,3 +104,72 @@ func TestSpecifiedRoute(t *testing.T) {
 	assert.Equal(t, RouteName(bs.Name), model.route.route.Name)
 	assert.Equal(t, bs.Spec.Application.Route.Host, model.route.route.Spec.Host)
 }
+
+func TestReencryptRoute(t *testing.T) {
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.Route = &bsv1alpha1.Route{
+		TLS: &bsv1alpha1.TLS{Termination: bsv1alpha1.TLSTerminationReencrypt},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).addToDefaultConfig("deployment.yaml", "janus-deployment.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, true, testObj.scheme)
+	assert.NoError(t, err)
+
+	assert.Equal(t, openshift.TLSTerminationReencrypt, model.route.route.Spec.TLS.Termination)
+
+	// serving certificate generated by OpenShift
+	assert.Equal(t, ServingCertSecretName(bs.Name), model.backstageService.service.Annotations[servingCertAnnotation])
+	container := model.backstageDeployment.container()
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{
+		Name:      utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, ServingCertSecretName(bs.Name)),
+		MountPath: servingCertDir,
+		ReadOnly:  true,
+	})
+
+	// HTTPS app-config
+	var httpsConfig *HTTPSAppConfig
+	for _, obj := range model.RuntimeObjects {
+		if ac, ok := obj.(*HTTPSAppConfig); ok {
+			httpsConfig = ac
+		}
+	}
+	assert.NotNil(t, httpsConfig)
+	assert.Equal(t, HTTPSAppConfigName(bs.Name), httpsConfig.ConfigMap.Name)
+	assert.Contains(t, httpsConfig.ConfigMap.Data[httpsAppConfigFile], "$file: "+servingCertDir+"/tls.crt")
+	assert.Contains(t, container.Args, defaultMountDir+"/"+httpsAppConfigFile)
+
+	assert.Equal(t, corev1.URISchemeHTTPS, container.ReadinessProbe.HTTPGet.Scheme)
+	assert.Equal(t, corev1.URISchemeHTTPS, container.LivenessProbe.HTTPGet.Scheme)
+
+	// edge by default
+	bs.Spec.Application.Route.TLS.Termination = ""
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, true, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, openshift.TLSTerminationEdge, model.route.route.Spec.TLS.Termination)
+	assert.NotContains(t, model.backstageService.service.Annotations, servingCertAnnotation)
+	assert.Equal(t, corev1.URISchemeHTTP, model.backstageDeployment.container().ReadinessProbe.HTTPGet.Scheme)
+}
+
+func TestPassthroughRouteWithCertificate(t *testing.T) {
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.Route = &bsv1alpha1.Route{
+		TLS: &bsv1alpha1.TLS{
+			Termination: bsv1alpha1.TLSTerminationPassthrough,
+			Certificate: "cert",
+			Key:         "key",
+		},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).addToDefaultConfig("deployment.yaml", "janus-deployment.yaml")
+
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, true, testObj.scheme)
+	assert.ErrorContains(t, err, "certificates can not be set with passthrough TLS termination")
+
+	// passthrough with the serving certificate
+	bs.Spec.Application.Route.TLS.Certificate = ""
+	bs.Spec.Application.Route.TLS.Key = ""
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, true, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, openshift.TLSTerminationPassthrough, model.route.route.Spec.TLS.Termination)
+}
@@ -18,13 +18,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -58,6 +61,10 @@ type BackstageModel struct {
 
 	route *BackstageRoute
 
+	trustedCABundle *TrustedCABundle
+
+	backendAuthSecret *BackendAuthSecret
+
 	RuntimeObjects []RuntimeObject
 
 	ExternalConfig ExternalConfig
@@ -71,11 +78,35 @@ type SpecifiedConfigMap struct {
 }
 
 type ExternalConfig struct {
+	// Operator default configuration loaded from the default ConfigMap(s) with the profile applied,
+	// if nil it is read from the default-config directory
+	DefaultConfig       map[string]string
 	RawConfig           map[string]string
 	AppConfigs          map[string]corev1.ConfigMap
 	ExtraFileConfigMaps map[string]corev1.ConfigMap
 	ExtraEnvConfigMaps  map[string]corev1.ConfigMap
 	DynamicPlugins      corev1.ConfigMap
+	// Operator level registry mirrors (--registry-mirrors flag),
+	// merged with the ones of registry-mirrors.yaml default configuration key
+	RegistryMirrors RegistryMirrors
+	// Proxy configuration of the cluster (OpenShift Proxy) or the Operator
+	Proxy ProxyConfig
+}
+
+// VisitManagedContainers visits the containers of Backstage and local database Pods the Operator manages the images of,
+// that is the containers with a role (like backend and dynamic plugins installer) and the local database container.
+// User added containers (like Spec.Application.ExtraContainers) and the ones of extra objects are not visited
+func (m *BackstageModel) VisitManagedContainers(visit func(container *corev1.Container)) {
+	if b := m.backstageDeployment; b != nil {
+		VisitContainers(b.podSpec(), func(container *corev1.Container) {
+			if b.containerRole(container) != "" {
+				visit(container)
+			}
+		})
+	}
+	if m.localDbStatefulSet != nil {
+		visit(m.localDbStatefulSet.container())
+	}
 }
 
 func (m *BackstageModel) setRuntimeObject(object RuntimeObject) {
@@ -88,34 +119,16 @@ func (m *BackstageModel) setRuntimeObject(object RuntimeObject) {
 	m.RuntimeObjects = append(m.RuntimeObjects, object)
 }
 
+// sortRuntimeObjects orders the objects by their ApplyStage, keeping the order of initialization within the stage
 func (m *BackstageModel) sortRuntimeObjects() {
-	// works with Go 1.18+
-	sort.Slice(m.RuntimeObjects, func(i, j int) bool {
-		_, ok1 := m.RuntimeObjects[i].(*DbStatefulSet)
-		_, ok2 := m.RuntimeObjects[j].(*BackstageDeployment)
-		if ok1 || ok2 {
-			return false
-		}
-		return true
-
+	sort.SliceStable(m.RuntimeObjects, func(i, j int) bool {
+		return ApplyStageOf(m.RuntimeObjects[i]) < ApplyStageOf(m.RuntimeObjects[j])
 	})
-
-	// this does not work for Go 1.20
-	// so image-build fails
-	//slices.SortFunc(m.RuntimeObjects,
-	//	func(a, b RuntimeObject) int {
-	//		_, ok1 := b.(*DbStatefulSet)
-	//		_, ok2 := b.(*BackstageDeployment)
-	//		if ok1 || ok2 {
-	//			return -1
-	//		}
-	//		return 1
-	//	})
 }
 
-// Registers config object
-func registerConfig(key string, factory ObjectFactory) {
-	runtimeConfig = append(runtimeConfig, ObjectConfig{Key: key, ObjectFactory: factory /*, need: need*/})
+// Registers config object, initialized after the objects with the keys listed in after
+func registerConfig(key string, factory ObjectFactory, after ...string) {
+	runtimeConfig = append(runtimeConfig, ObjectConfig{Key: key, ObjectFactory: factory, Registration: Registration{After: after}})
 }
 
 // InitObjects performs a main loop for configuring and making the array of objects to reconcile
@@ -132,14 +145,19 @@ func InitObjects(ctx context.Context, backstage bsv1alpha1.Backstage, externalCo
 
 	model := &BackstageModel{RuntimeObjects: make([]RuntimeObject, 0), ExternalConfig: externalConfig, localDbEnabled: backstage.Spec.IsLocalDbEnabled(), isOpenshift: isOpenshift}
 
+	configs, err := sortRegistrations(runtimeConfig, func(c ObjectConfig) (string, Registration) { return c.Key, c.Registration })
+	if err != nil {
+		return nil, fmt.Errorf("failed to order registered objects, reason: %s", err)
+	}
+
 	// looping through the registered runtimeConfig objects initializing the model
-	for _, conf := range runtimeConfig {
+	for _, conf := range configs {
 
 		// creating the instance of backstageObject
 		backstageObject := conf.ObjectFactory.newBackstageObject()
 
 		var obj = backstageObject.EmptyObject()
-		if err := utils.ReadYamlFile(utils.DefFile(conf.Key), obj); err != nil {
+		if err := readDefaultConfig(externalConfig.DefaultConfig, backstage.Spec.Profile, conf.Key, obj); err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
 				return nil, fmt.Errorf("failed to read default value for the key %s, reason: %s", conf.Key, err)
 			}
@@ -147,7 +165,7 @@ func InitObjects(ctx context.Context, backstage bsv1alpha1.Backstage, externalCo
 			backstageObject.setObject(obj)
 		}
 
-		// reading configuration defined in BackstageCR.Spec.RawConfigContent ConfigMap
+		// reading configuration defined in BackstageCR.Spec.RawRuntimeConfig ConfigMap
 		// if present, backstageObject's default configuration will be overridden
 		overlay, overlayExist := externalConfig.RawConfig[conf.Key]
 		if overlayExist {
@@ -158,6 +176,12 @@ func InitObjects(ctx context.Context, backstage bsv1alpha1.Backstage, externalCo
 			}
 		}
 
+		// patches defined in BackstageCR.Spec.RawRuntimeConfig ConfigMap
+		// are applied over the default or overlaid configuration
+		if err := applyPatches(externalConfig.RawConfig, conf.Key, backstageObject); err != nil {
+			return nil, err
+		}
+
 		// apply spec and add the object to the model and list
 		if added, err := backstageObject.addToModel(model, backstage); err != nil {
 			return nil, fmt.Errorf("failed to initialize %s reason: %s", backstageObject, err)
@@ -166,6 +190,19 @@ func InitObjects(ctx context.Context, backstage bsv1alpha1.Backstage, externalCo
 		}
 	}
 
+	// additional objects defined in BackstageCR.Spec.RawRuntimeConfig ConfigMap with extra-*.yaml keys
+	extras, err := readExtraObjects(externalConfig.RawConfig, backstage.Namespace, scheme)
+	if err != nil {
+		return nil, err
+	}
+	for _, extra := range extras {
+		if added, err := extra.addToModel(model, backstage); err != nil {
+			return nil, fmt.Errorf("failed to initialize %s reason: %s", extra.key, err)
+		} else if added {
+			setMetaInfo(extra, backstage, ownsRuntime, scheme)
+		}
+	}
+
 	// set generic metainfo and validate all
 	for _, v := range model.RuntimeObjects {
 		err := v.validate(model, backstage)
@@ -174,12 +211,62 @@ func InitObjects(ctx context.Context, backstage bsv1alpha1.Backstage, externalCo
 		}
 	}
 
-	// sort for reconciliation number optimization
+	// rewrite images and plugin packages to the registry mirrors, if any
+	if err := model.applyRegistryMirrors(backstage.Spec.Profile); err != nil {
+		return nil, err
+	}
+
+	// sort in the order of dependencies
 	model.sortRuntimeObjects()
 
 	return model, nil
 }
 
+// reads the default configuration of the key from the Operator default ConfigMap if loaded
+// or from the default-config directory otherwise, where the profile subdirectory takes precedence.
+// Empty configuration (no or blank document) means the key is not configured, so a profile can disable the key defined in the base
+func readDefaultConfig(defaultConfig map[string]string, profile string, key string, obj client.Object) error {
+	content, err := readDefaultContent(defaultConfig, profile, key)
+	if err != nil {
+		return err
+	}
+	empty := obj.DeepCopyObject()
+	if err := utils.ReadYaml(content, obj); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("empty default configuration for the key %s: %w", key, os.ErrNotExist)
+		}
+		return err
+	}
+	if reflect.DeepEqual(obj, empty) {
+		return fmt.Errorf("empty default configuration for the key %s: %w", key, os.ErrNotExist)
+	}
+	return nil
+}
+
+// reads the raw content of the default configuration key, see readDefaultConfig
+func readDefaultContent(defaultConfig map[string]string, profile string, key string) ([]byte, error) {
+	if defaultConfig == nil {
+		var content []byte
+		var err error
+		if profile != "" {
+			// missing profile is an error, not a missing key, same as for the profile ConfigMap
+			if _, err := os.Stat(filepath.Clean(utils.DefFile(profile, ""))); err != nil {
+				return nil, fmt.Errorf("failed to load default configuration profile %s, reason: %s", profile, err)
+			}
+			content, err = os.ReadFile(filepath.Clean(utils.DefFile(profile, key)))
+		}
+		if profile == "" || errors.Is(err, os.ErrNotExist) {
+			content, err = os.ReadFile(filepath.Clean(utils.DefFile("", key)))
+		}
+		return content, err
+	}
+	value, ok := defaultConfig[key]
+	if !ok {
+		return nil, fmt.Errorf("no default configuration for the key %s: %w", key, os.ErrNotExist)
+	}
+	return []byte(value), nil
+}
+
 // Every RuntimeObject.setMetaInfo should as minimum call this
 func setMetaInfo(modelObject RuntimeObject, backstage bsv1alpha1.Backstage, ownsRuntime bool, scheme *runtime.Scheme) {
 	modelObject.setMetaInfo(backstage.Name)
@@ -136,3 +136,91 @@ func TestAddToModel(t *testing.T) {
 	assert.Equal(t, testService, *rm.backstageService)
 	assert.Equal(t, testService, *rm.RuntimeObjects[0].(*BackstageService))
 }
+
+func TestDefaultConfigFromConfigMap(t *testing.T) {
+
+	bs := v1alpha1.Backstage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bs",
+			Namespace: "ns123",
+		},
+		Spec: v1alpha1.BackstageSpec{
+			Database: &v1alpha1.Database{
+				EnableLocalDb: ptr.To(false),
+			},
+		},
+	}
+
+	// no default-config directory, so it can be taken from the ConfigMap only
+	testObj := createBackstageTest(bs).withDefaultConfig(false)
+
+	deployment, err := readTestYamlFile("default-config/deployment.yaml")
+	assert.NoError(t, err)
+	service, err := readTestYamlFile("default-config/service.yaml")
+	assert.NoError(t, err)
+	testObj.externalConfig.DefaultConfig = map[string]string{
+		"deployment.yaml": string(deployment),
+		"service.yaml":    string(service),
+	}
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(model.RuntimeObjects))
+	assert.Equal(t, DeploymentName(bs.Name), model.backstageDeployment.Object().GetName())
+
+	// the directory is not used as a fallback
+	testObj = createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.DefaultConfig = map[string]string{}
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.Error(t, err)
+}
+
+func TestDefaultConfigProfile(t *testing.T) {
+
+	bs := v1alpha1.Backstage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bs",
+			Namespace: "ns123",
+		},
+		Spec: v1alpha1.BackstageSpec{
+			Profile: "test-profile",
+			Database: &v1alpha1.Database{
+				EnableLocalDb: ptr.To(false),
+			},
+		},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	// deployment.yaml taken from the profile
+	assert.Equal(t, "profile-backstage-backend", model.backstageDeployment.container().Name)
+	assert.Equal(t, int32(2), *model.backstageDeployment.deployment.Spec.Replicas)
+	// service.yaml taken from the base
+	assert.NotNil(t, model.backstageService)
+
+	// db-statefulset.yaml disabled by the profile
+	bs.Spec.Database.EnableLocalDb = ptr.To(true)
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "LocalDb StatefulSet not configured")
+}
+
+func TestDefaultConfigProfileNotFound(t *testing.T) {
+
+	bs := v1alpha1.Backstage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bs",
+			Namespace: "ns123",
+		},
+		Spec: v1alpha1.BackstageSpec{
+			Profile: "no-such-profile",
+		},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	_, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "failed to load default configuration profile no-such-profile")
+}
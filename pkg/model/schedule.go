@@ -0,0 +1,76 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EvaluateSchedule returns the state of the schedule at the given time.
+// Backstage is scheduled to be running if the next stop comes before the next start
+func EvaluateSchedule(schedule bsv1alpha1.Schedule, now time.Time) (*bsv1alpha1.ScheduleStatus, error) {
+	loc := time.UTC
+	if schedule.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(schedule.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %s, reason: %s", schedule.TimeZone, err)
+		}
+	}
+
+	start, err := parseCron(schedule.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule start %s, reason: %s", schedule.Start, err)
+	}
+	stop, err := parseCron(schedule.Stop)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule stop %s, reason: %s", schedule.Stop, err)
+	}
+
+	now = now.In(loc)
+	nextStart, nextStop := start.Next(now), stop.Next(now)
+	if nextStart.IsZero() || nextStop.IsZero() {
+		return nil, fmt.Errorf("schedule start %s or stop %s never happens", schedule.Start, schedule.Stop)
+	}
+
+	if nextStop.Before(nextStart) {
+		return &bsv1alpha1.ScheduleStatus{Active: true, NextTransition: metav1.NewTime(nextStop.UTC())}, nil
+	}
+	return &bsv1alpha1.ScheduleStatus{Active: false, NextTransition: metav1.NewTime(nextStart.UTC())}, nil
+}
+
+// cronParser accepts the standard 5 fields cron expressions only, no descriptors (like @daily or @every 1h)
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// parseCron parses the standard 5 fields cron expression, the time zone is defined by Schedule.TimeZone only,
+// so TZ= and CRON_TZ= prefixes are not allowed
+func parseCron(expr string) (cron.Schedule, error) {
+	if trimmed := strings.TrimSpace(expr); strings.HasPrefix(trimmed, "TZ=") || strings.HasPrefix(trimmed, "CRON_TZ=") {
+		return nil, fmt.Errorf("time zone prefix is not allowed, use timeZone instead")
+	}
+	return cronParser.Parse(expr)
+}
+
+// isScheduledOff returns true if Backstage is out of the scheduled running window,
+// according to the schedule state evaluated by the controller
+func isScheduledOff(backstage bsv1alpha1.Backstage) bool {
+	return backstage.Spec.Schedule != nil && backstage.Status.Schedule != nil && !backstage.Status.Schedule.Active
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var businessHours = bsv1alpha1.Schedule{
+	Start: "0 8 * * 1-5",
+	Stop:  "0 18 * * 1-5",
+}
+
+func TestEvaluateSchedule(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 6, 10, 30, 0, 0, time.UTC)
+	status, err := EvaluateSchedule(businessHours, now)
+	assert.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.Equal(t, time.Date(2024, 3, 6, 18, 0, 0, 0, time.UTC), status.NextTransition.Time)
+
+	// Wednesday evening
+	now = time.Date(2024, 3, 6, 19, 0, 0, 0, time.UTC)
+	status, err = EvaluateSchedule(businessHours, now)
+	assert.NoError(t, err)
+	assert.False(t, status.Active)
+	assert.Equal(t, time.Date(2024, 3, 7, 8, 0, 0, 0, time.UTC), status.NextTransition.Time)
+
+	// Saturday
+	now = time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+	status, err = EvaluateSchedule(businessHours, now)
+	assert.NoError(t, err)
+	assert.False(t, status.Active)
+	assert.Equal(t, time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC), status.NextTransition.Time)
+}
+
+func TestEvaluateScheduleTimeZone(t *testing.T) {
+	schedule := businessHours
+	schedule.TimeZone = "Europe/Prague"
+
+	// 8:30 in Prague (UTC+1)
+	now := time.Date(2024, 3, 6, 7, 30, 0, 0, time.UTC)
+	status, err := EvaluateSchedule(schedule, now)
+	assert.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.Equal(t, time.Date(2024, 3, 6, 17, 0, 0, 0, time.UTC), status.NextTransition.Time.UTC())
+}
+
+func TestInvalidSchedule(t *testing.T) {
+	now := time.Date(2024, 3, 6, 10, 30, 0, 0, time.UTC)
+
+	_, err := EvaluateSchedule(bsv1alpha1.Schedule{Start: "0 8 * *", Stop: "0 18 * * *"}, now)
+	assert.ErrorContains(t, err, "invalid schedule start")
+
+	_, err = EvaluateSchedule(bsv1alpha1.Schedule{Start: "0 8 * * *", Stop: "0 18 * * *", TimeZone: "Nowhere/Nothing"}, now)
+	assert.ErrorContains(t, err, "invalid schedule time zone")
+
+	// descriptors
+	_, err = EvaluateSchedule(bsv1alpha1.Schedule{Start: "@daily", Stop: "0 18 * * *"}, now)
+	assert.ErrorContains(t, err, "invalid schedule start @daily")
+	_, err = EvaluateSchedule(bsv1alpha1.Schedule{Start: "0 8 * * *", Stop: "@every 1h"}, now)
+	assert.ErrorContains(t, err, "invalid schedule stop @every 1h")
+
+	// time zone prefixes
+	_, err = EvaluateSchedule(bsv1alpha1.Schedule{Start: "TZ=Europe/Prague 0 8 * * *", Stop: "0 18 * * *"}, now)
+	assert.ErrorContains(t, err, "time zone prefix is not allowed")
+	_, err = EvaluateSchedule(bsv1alpha1.Schedule{Start: "0 8 * * *", Stop: "CRON_TZ=Europe/Prague 0 18 * * *"}, now)
+	assert.ErrorContains(t, err, "time zone prefix is not allowed")
+
+	// 6 fields (with seconds)
+	_, err = EvaluateSchedule(bsv1alpha1.Schedule{Start: "0 0 8 * * *", Stop: "0 18 * * *"}, now)
+	assert.ErrorContains(t, err, "invalid schedule start")
+}
+
+func TestScheduledOff(t *testing.T) {
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.Replicas = ptr.To(int32(2))
+	bs.Spec.Schedule = businessHours.DeepCopy()
+	bs.Status.Schedule = &bsv1alpha1.ScheduleStatus{Active: false}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), *model.backstageDeployment.deployment.Spec.Replicas)
+
+	bs.Status.Schedule.Active = true
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), *model.backstageDeployment.deployment.Spec.Replicas)
+}
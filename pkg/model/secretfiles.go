@@ -37,6 +37,7 @@ type SecretFiles struct {
 	Secret    *corev1.Secret
 	MountPath string
 	Key       string
+	MountMode v1alpha1.MountMode
 }
 
 func init() {
@@ -54,7 +55,8 @@ func addSecretFiles(spec v1alpha1.BackstageSpec, deployment *appsv1.Deployment)
 	}
 
 	for _, sec := range spec.Application.ExtraFiles.Secrets {
-		if sec.Key == "" {
+		// the Secret is not read, so the keys are unknown for SubPath mounts
+		if sec.Key == "" && (sec.MountMode == "" || sec.MountMode == v1alpha1.MountModeSubPath) {
 			return fmt.Errorf("key is required to mount extra file with secret %s", sec.Name)
 		}
 		sf := SecretFiles{
@@ -66,6 +68,7 @@ func addSecretFiles(spec v1alpha1.BackstageSpec, deployment *appsv1.Deployment)
 			},
 			MountPath: mp,
 			Key:       sec.Key,
+			MountMode: sec.MountMode,
 		}
 		sf.updatePod(deployment)
 	}
@@ -110,6 +113,6 @@ func (p *SecretFiles) setMetaInfo(backstageName string) {
 // implementation of BackstagePodContributor interface
 func (p *SecretFiles) updatePod(depoyment *appsv1.Deployment) {
 
-	utils.MountFilesFrom(&depoyment.Spec.Template.Spec, &depoyment.Spec.Template.Spec.Containers[0], utils.SecretObjectKind,
-		p.Secret.Name, p.MountPath, p.Key, p.Secret.StringData)
+	mountFiles(depoyment, utils.SecretObjectKind, p.Secret.Name, p.MountPath, p.Key, p.Secret.StringData,
+		p.MountMode, extraFilesProjectedDir)
 }
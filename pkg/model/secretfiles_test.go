@@ -84,7 +84,7 @@ func TestSpecifiedSecretFiles(t *testing.T) {
 	bs := *secretFilesTestBackstage.DeepCopy()
 	sf := &bs.Spec.Application.ExtraFiles.Secrets
 	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret1", Key: "conf.yaml"})
-	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret2", Key: "conf.yaml"})
+	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret2", Key: "conf2.yaml"})
 
 	testObj := createBackstageTest(bs).withDefaultConfig(true)
 
@@ -100,7 +100,7 @@ func TestSpecifiedSecretFiles(t *testing.T) {
 	assert.Equal(t, 0, len(deployment.deployment.Spec.Template.Spec.Containers[0].Args))
 	assert.Equal(t, 2, len(deployment.deployment.Spec.Template.Spec.Volumes))
 
-	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret("secret1"), deployment.podSpec().Volumes[0].Name)
+	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, "secret1"), deployment.podSpec().Volumes[0].Name)
 
 }
 
@@ -122,6 +122,34 @@ func TestDefaultAndSpecifiedSecretFiles(t *testing.T) {
 	assert.Equal(t, 2, len(deployment.deployment.Spec.Template.Spec.Containers[0].VolumeMounts))
 	assert.Equal(t, 0, len(deployment.deployment.Spec.Template.Spec.Containers[0].Args))
 	assert.Equal(t, 2, len(deployment.deployment.Spec.Template.Spec.Volumes))
-	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret("secret1"), deployment.podSpec().Volumes[1].Name)
+	assert.Equal(t, utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, "secret1"), deployment.podSpec().Volumes[1].Name)
 
 }
+
+func TestSecretFilesMountModes(t *testing.T) {
+
+	bs := *secretFilesTestBackstage.DeepCopy()
+	sf := &bs.Spec.Application.ExtraFiles.Secrets
+	// key is not required
+	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret1", MountMode: bsv1alpha1.MountModeDirectory})
+	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret2", MountMode: bsv1alpha1.MountModeProjected})
+	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret3", Key: "conf.yaml", MountMode: bsv1alpha1.MountModeProjected})
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	deployment := model.backstageDeployment
+	assert.Equal(t, 2, len(deployment.container().VolumeMounts))
+	assert.Equal(t, "/my/path/secret1", deployment.container().VolumeMounts[0].MountPath)
+	assert.Empty(t, deployment.container().VolumeMounts[0].SubPath)
+	assert.Nil(t, deployment.podSpec().Volumes[0].Secret.Items)
+	assert.Equal(t, "/my/path/extra-files", deployment.container().VolumeMounts[1].MountPath)
+	assert.Equal(t, 2, len(deployment.podSpec().Volumes[1].Projected.Sources))
+
+	// key is required for SubPath
+	*sf = append(*sf, bsv1alpha1.ObjectKeyRef{Name: "secret4"})
+	_, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.ErrorContains(t, err, "key is required to mount extra file with secret secret4")
+}
@@ -0,0 +1,46 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strconv"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+)
+
+const (
+	// SuspendAnnotation of Backstage CR, if "true", scales Backstage and local database down to zero replicas,
+	// same as Spec.Suspend
+	SuspendAnnotation = "rhdh.redhat.com/suspend"
+	// ReconcilePausedAnnotation of Backstage CR, if "true", stops the Operator from changing the runtime objects,
+	// for example, for manual debugging
+	ReconcilePausedAnnotation = "rhdh.redhat.com/reconcile-paused"
+)
+
+// IsSuspended returns true if Backstage is requested to be scaled down to zero replicas,
+// either by Spec.Suspend, by SuspendAnnotation or by Spec.Schedule
+func IsSuspended(backstage bsv1alpha1.Backstage) bool {
+	return backstage.Spec.Suspend || annotationEnabled(backstage, SuspendAnnotation) || isScheduledOff(backstage)
+}
+
+// IsReconcilePaused returns true if the reconciliation of Backstage runtime objects is paused by ReconcilePausedAnnotation
+func IsReconcilePaused(backstage bsv1alpha1.Backstage) bool {
+	return annotationEnabled(backstage, ReconcilePausedAnnotation)
+}
+
+func annotationEnabled(backstage bsv1alpha1.Backstage, key string) bool {
+	enabled, err := strconv.ParseBool(backstage.GetAnnotations()[key])
+	return err == nil && enabled
+}
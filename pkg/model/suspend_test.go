@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuspended(t *testing.T) {
+	bs := *dbStatefulSetBackstage.DeepCopy()
+	bs.Spec.Suspend = true
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).withLocalDb()
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(0), *model.backstageDeployment.deployment.Spec.Replicas)
+	assert.Equal(t, int32(0), *model.localDbStatefulSet.statefulSet.Spec.Replicas)
+	// the rest of the objects are kept
+	assert.NotNil(t, model.LocalDbSecret)
+	assert.NotNil(t, model.backstageService)
+}
+
+func TestSuspendedByAnnotation(t *testing.T) {
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.Replicas = ptr.To(int32(3))
+	bs.SetAnnotations(map[string]string{SuspendAnnotation: "true"})
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(0), *model.backstageDeployment.deployment.Spec.Replicas)
+
+	// resumed
+	bs.SetAnnotations(map[string]string{SuspendAnnotation: "false"})
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(3), *model.backstageDeployment.deployment.Spec.Replicas)
+}
+
+func TestReconcilePaused(t *testing.T) {
+	bs := *deploymentTestBackstage.DeepCopy()
+	assert.False(t, IsReconcilePaused(bs))
+
+	bs.SetAnnotations(map[string]string{ReconcilePausedAnnotation: "true"})
+	assert.True(t, IsReconcilePaused(bs))
+	assert.False(t, IsSuspended(bs))
+
+	bs.SetAnnotations(map[string]string{ReconcilePausedAnnotation: "no"})
+	assert.False(t, IsReconcilePaused(bs))
+}
@@ -0,0 +1,163 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"path/filepath"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// OpenShift injects the cluster-wide trusted CA bundle to the ConfigMap labeled with it
+	injectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+	// key of the injected CA bundle
+	injectedCABundleKey = "ca-bundle.crt"
+
+	trustedCAContainerName = "merge-trusted-ca"
+	trustedCAVolumeName    = "trusted-ca-bundle"
+	// directory the merged bundle is mounted to
+	trustedCADir = "/opt/app-root/src/trusted-ca"
+	// directory the CA sources are mounted to, each one to its own subdirectory
+	trustedCASourcesDir = "/opt/app-root/src/trusted-ca-sources"
+	trustedCABundleFile = "ca-bundle.crt"
+
+	// concatenates all the files of the sources, a file may not end with a newline
+	mergeTrustedCAScript = `for f in ` + trustedCASourcesDir + `/*/*; do
+  if [ -f "$f" ]; then cat "$f"; echo; fi
+done > ` + trustedCADir + `/` + trustedCABundleFile
+)
+
+type TrustedCABundleFactory struct{}
+
+func (f TrustedCABundleFactory) newBackstageObject() RuntimeObject {
+	return &TrustedCABundle{}
+}
+
+// TrustedCABundle is the ConfigMap the cluster-wide trusted CA bundle is injected to (OpenShift only)
+type TrustedCABundle struct {
+	ConfigMap *corev1.ConfigMap
+}
+
+func init() {
+	registerConfig("trusted-ca-bundle.yaml", TrustedCABundleFactory{})
+}
+
+func TrustedCABundleName(backstageName string) string {
+	return utils.GenerateRuntimeObjectName(backstageName, "backstage-trusted-ca-bundle")
+}
+
+// implementation of RuntimeObject interface
+func (b *TrustedCABundle) Object() client.Object {
+	return b.ConfigMap
+}
+
+// implementation of RuntimeObject interface
+func (b *TrustedCABundle) setObject(obj client.Object) {
+	b.ConfigMap = nil
+	if obj != nil {
+		b.ConfigMap = obj.(*corev1.ConfigMap)
+	}
+}
+
+// implementation of RuntimeObject interface
+func (b *TrustedCABundle) EmptyObject() client.Object {
+	return &corev1.ConfigMap{}
+}
+
+// implementation of RuntimeObject interface
+func (b *TrustedCABundle) addToModel(model *BackstageModel, backstage bsv1alpha1.Backstage) (bool, error) {
+	if !model.isOpenshift || !backstage.Spec.IsTrustedCASpecified() || !backstage.Spec.Application.TrustedCA.InjectClusterCABundle {
+		return false, nil
+	}
+	if b.ConfigMap == nil {
+		b.ConfigMap = &corev1.ConfigMap{}
+	}
+	// the content is managed by OpenShift
+	b.ConfigMap.Data = nil
+	utils.GenerateLabel(&b.ConfigMap.Labels, injectTrustedCABundleLabel, "true")
+
+	model.trustedCABundle = b
+	model.setRuntimeObject(b)
+	return true, nil
+}
+
+// implementation of RuntimeObject interface
+func (b *TrustedCABundle) validate(_ *BackstageModel, _ bsv1alpha1.Backstage) error {
+	return nil
+}
+
+func (b *TrustedCABundle) setMetaInfo(backstageName string) {
+	b.ConfigMap.SetName(TrustedCABundleName(backstageName))
+}
+
+// addTrustedCA merges the trusted CA sources into a single bundle with the init container running before all others
+// and makes the containers of backend and dynamic plugins installer roles use it
+func addTrustedCA(spec bsv1alpha1.BackstageSpec, b *BackstageDeployment, model *BackstageModel) {
+
+	if !spec.IsTrustedCASpecified() {
+		return
+	}
+	podSpec := b.podSpec()
+
+	// the merge container runs the backend image, which has the shell
+	image := podSpec.Containers[0].Image
+	VisitContainers(podSpec, func(container *corev1.Container) {
+		if b.containerRole(container) == BackendContainerRole {
+			image = container.Image
+		}
+	})
+
+	merge := corev1.Container{
+		Name:    trustedCAContainerName,
+		Image:   image,
+		Command: []string{"/bin/sh", "-c", mergeTrustedCAScript},
+	}
+	podSpec.InitContainers = append([]corev1.Container{merge}, podSpec.InitContainers...)
+	mergeContainer := &podSpec.InitContainers[0]
+
+	pm := utils.PodMutator{PodSpec: podSpec, Container: mergeContainer}
+	bundleVolume := pm.AddVolume(corev1.Volume{Name: trustedCAVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+	pm.AddVolumeMount(corev1.VolumeMount{Name: bundleVolume, MountPath: trustedCADir})
+
+	addSource := func(kind utils.ObjectKind, name, key string) {
+		utils.MountDirFrom(podSpec, mergeContainer, kind, name,
+			filepath.Join(trustedCASourcesDir, utils.GenerateVolumeNameFromCmOrSecret(kind, name)), key)
+	}
+	for _, cm := range spec.Application.TrustedCA.ConfigMaps {
+		addSource(utils.ConfigMapObjectKind, cm.Name, cm.Key)
+	}
+	for _, sec := range spec.Application.TrustedCA.Secrets {
+		addSource(utils.SecretObjectKind, sec.Name, sec.Key)
+	}
+	if model.trustedCABundle != nil {
+		addSource(utils.ConfigMapObjectKind, model.trustedCABundle.ConfigMap.Name, injectedCABundleKey)
+	}
+
+	VisitContainers(podSpec, func(container *corev1.Container) {
+		if role := b.containerRole(container); role == BackendContainerRole || role == DynamicPluginsInstallerContainerRole {
+			useTrustedCA(container, bundleVolume)
+		}
+	})
+}
+
+func useTrustedCA(container *corev1.Container, bundleVolume string) {
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: bundleVolume, MountPath: trustedCADir, ReadOnly: true})
+	container.Env = append(container.Env, corev1.EnvVar{Name: "NODE_EXTRA_CA_CERTS", Value: filepath.Join(trustedCADir, trustedCABundleFile)})
+}
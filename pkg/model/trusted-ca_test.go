@@ -0,0 +1,145 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	bsv1alpha1 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha1"
+	"redhat-developer/red-hat-developer-hub-operator/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedCA(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.TrustedCA = &bsv1alpha1.TrustedCA{
+		ConfigMaps:            []bsv1alpha1.ObjectKeyRef{{Name: "corporate-ca"}},
+		Secrets:               []bsv1alpha1.ObjectKeyRef{{Name: "keycloak-tls", Key: "ca.crt"}},
+		InjectClusterCABundle: true,
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true).
+		addToDefaultConfig("deployment.yaml", "janus-deployment.yaml")
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, true, testObj.scheme)
+	assert.NoError(t, err)
+
+	// ConfigMap the cluster CA bundle injected to
+	assert.NotNil(t, model.trustedCABundle)
+	assert.Equal(t, TrustedCABundleName(bs.Name), model.trustedCABundle.ConfigMap.Name)
+	assert.Equal(t, "true", model.trustedCABundle.ConfigMap.Labels[injectTrustedCABundleLabel])
+
+	podSpec := model.backstageDeployment.podSpec()
+	// sources are merged before the dynamic plugins installed
+	merge := podSpec.InitContainers[0]
+	assert.Equal(t, trustedCAContainerName, merge.Name)
+	assert.Equal(t, model.backstageDeployment.container().Image, merge.Image)
+	mounts := map[string]string{}
+	for _, vm := range merge.VolumeMounts {
+		mounts[vm.MountPath] = vm.Name
+	}
+	assert.Equal(t, 4, len(mounts))
+	assert.Equal(t, trustedCAVolumeName, mounts[trustedCADir])
+	assert.Contains(t, mounts, trustedCASourcesDir+"/cm-corporate-ca")
+	assert.Contains(t, mounts, trustedCASourcesDir+"/secret-keycloak-tls")
+	assert.Contains(t, mounts, trustedCASourcesDir+"/cm-bs-backstage-trusted-ca-bundle")
+
+	for _, v := range podSpec.Volumes {
+		if v.Name == utils.GenerateVolumeNameFromCmOrSecret(utils.SecretObjectKind, "keycloak-tls") {
+			assert.Equal(t, []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}}, v.Secret.Items)
+		}
+	}
+
+	installer := podSpec.InitContainers[1]
+	assert.Equal(t, dynamicPluginInitContainerName, installer.Name)
+	for _, c := range []corev1.Container{installer, *model.backstageDeployment.container()} {
+		assert.Contains(t, c.Env, corev1.EnvVar{Name: "NODE_EXTRA_CA_CERTS", Value: trustedCADir + "/" + trustedCABundleFile})
+		assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: trustedCAVolumeName, MountPath: trustedCADir, ReadOnly: true})
+	}
+
+	// no injection on Kubernetes
+	model, err = InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Nil(t, model.trustedCABundle)
+	assert.Equal(t, 3, len(model.backstageDeployment.podSpec().InitContainers[0].VolumeMounts))
+}
+
+func TestNoTrustedCA(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.TrustedCA = &bsv1alpha1.TrustedCA{}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, true, testObj.scheme)
+	assert.NoError(t, err)
+	assert.Nil(t, model.trustedCABundle)
+	for _, ic := range model.backstageDeployment.podSpec().InitContainers {
+		assert.NotEqual(t, trustedCAContainerName, ic.Name)
+	}
+}
+
+func TestTrustedCAContainerRoles(t *testing.T) {
+
+	bs := *deploymentTestBackstage.DeepCopy()
+	bs.Spec.Application.TrustedCA = &bsv1alpha1.TrustedCA{
+		ConfigMaps: []bsv1alpha1.ObjectKeyRef{{Name: "corporate-ca"}},
+	}
+
+	testObj := createBackstageTest(bs).withDefaultConfig(true)
+	testObj.externalConfig.RawConfig["deployment.yaml"] = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bs
+spec:
+  template:
+    metadata:
+      annotations:
+        container-role.rhdh.redhat.com/plugins: dynamic-plugins-installer
+        container-role.rhdh.redhat.com/shipper: log-shipper
+    spec:
+      initContainers:
+        - name: plugins
+          image: quay.io/janus-idp/backstage-showcase:next
+        - name: other-init
+          image: busybox
+      containers:
+        - name: backstage-backend
+          image: quay.io/janus-idp/backstage-showcase:next
+        - name: shipper
+          image: shipper
+`
+
+	model, err := InitObjects(context.TODO(), bs, testObj.externalConfig, true, false, testObj.scheme)
+	assert.NoError(t, err)
+
+	caEnv := corev1.EnvVar{Name: "NODE_EXTRA_CA_CERTS", Value: trustedCADir + "/" + trustedCABundleFile}
+	podSpec := model.backstageDeployment.podSpec()
+	assert.Equal(t, model.backstageDeployment.container().Image, podSpec.InitContainers[0].Image)
+	VisitContainers(podSpec, func(c *corev1.Container) {
+		switch c.Name {
+		case "plugins", "backstage-backend":
+			assert.Contains(t, c.Env, caEnv, c.Name)
+		case "other-init", "shipper":
+			assert.NotContains(t, c.Env, caEnv, c.Name)
+		}
+	})
+}
@@ -15,7 +15,11 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"reflect"
+	"sort"
 
 	"k8s.io/utils/ptr"
 
@@ -29,11 +33,67 @@ const (
 
 type ObjectKind string
 
+// PodMutator is a registry of the Volumes of PodSpec and VolumeMounts of its Container.
+// The Volume of the same source is added only once, and the name taken by another source is made unique,
+// so ConfigMaps and Secrets can be mounted from several places of Backstage CR without collisions
 type PodMutator struct {
 	PodSpec   *corev1.PodSpec
 	Container *corev1.Container
 }
 
+// AddVolume adds the Volume to the PodSpec, unless the Volume with the same source is already there,
+// and returns the name of the Volume to refer to from VolumeMounts.
+// If the volume name is taken by another source, the name is suffixed with the hash of the source
+func (p PodMutator) AddVolume(volume corev1.Volume) string {
+	for _, v := range p.PodSpec.Volumes {
+		if reflect.DeepEqual(v.VolumeSource, volume.VolumeSource) {
+			return v.Name
+		}
+	}
+	if p.volume(volume.Name) != nil {
+		src, _ := json.Marshal(volume.VolumeSource)
+		volume.Name = hashedName(volume.Name, string(src))
+	}
+	p.PodSpec.Volumes = append(p.PodSpec.Volumes, volume)
+	return volume.Name
+}
+
+// AddVolumeMount adds the VolumeMount to the Container, unless the same one is already there.
+// Conflicting mount paths are not checked here but reported by ValidateVolumeMounts
+func (p PodMutator) AddVolumeMount(volumeMount corev1.VolumeMount) {
+	for _, vm := range p.Container.VolumeMounts {
+		if reflect.DeepEqual(vm, volumeMount) {
+			return
+		}
+	}
+	p.Container.VolumeMounts = append(p.Container.VolumeMounts, volumeMount)
+}
+
+func (p PodMutator) volume(name string) *corev1.Volume {
+	for i, v := range p.PodSpec.Volumes {
+		if v.Name == name {
+			return &p.PodSpec.Volumes[i]
+		}
+	}
+	return nil
+}
+
+// ValidateVolumeMounts checks that no mount path is used more than once in any container of the PodSpec
+func ValidateVolumeMounts(podSpec *corev1.PodSpec) error {
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, c := range containers {
+		paths := map[string]string{}
+		for _, vm := range c.VolumeMounts {
+			mountPath := filepath.Clean(vm.MountPath)
+			if other, ok := paths[mountPath]; ok {
+				return fmt.Errorf("mount path %s of container %s is used by both volume %s and volume %s", mountPath, c.Name, other, vm.Name)
+			}
+			paths[mountPath] = vm.Name
+		}
+	}
+	return nil
+}
+
 // MountFilesFrom adds Volume to specified podSpec and related VolumeMounts to specified belonging to this podSpec container
 // from ConfigMap or Secret volume source
 // podSpec - PodSpec to add Volume to
@@ -46,36 +106,112 @@ type PodMutator struct {
 // data - key:value pairs from the object. should be specified if fileName specified
 func MountFilesFrom(podSpec *corev1.PodSpec, container *corev1.Container, kind ObjectKind, objectName, mountPath, fileName string, data map[string]string) {
 
-	volName := GenerateVolumeNameFromCmOrSecret(objectName)
+	pm := PodMutator{PodSpec: podSpec, Container: container}
+	volName := pm.AddVolume(corev1.Volume{
+		Name:         GenerateVolumeNameFromCmOrSecret(kind, objectName),
+		VolumeSource: volumeSource(kind, objectName, nil),
+	})
+
+	if data != nil {
+		for _, file := range sortedKeys(data) {
+			if fileName == "" || fileName == file {
+				pm.AddVolumeMount(corev1.VolumeMount{Name: volName, MountPath: filepath.Join(mountPath, file), SubPath: file, ReadOnly: true})
+			}
+		}
+	} else {
+		pm.AddVolumeMount(corev1.VolumeMount{Name: volName, MountPath: filepath.Join(mountPath, objectName), ReadOnly: true})
+	}
+
+}
+
+// MountDirFrom adds Volume to specified podSpec and VolumeMount of the whole ConfigMap or Secret
+// as a directory to the container, so the files are updated when the object changes.
+// If fileName specified, only this key of the object is mounted to the directory
+func MountDirFrom(podSpec *corev1.PodSpec, container *corev1.Container, kind ObjectKind, objectName, dirPath, fileName string) {
+
+	var items []corev1.KeyToPath
+	if fileName != "" {
+		items = []corev1.KeyToPath{{Key: fileName, Path: fileName}}
+	}
+	pm := PodMutator{PodSpec: podSpec, Container: container}
+	volName := pm.AddVolume(corev1.Volume{
+		Name:         GenerateVolumeNameFromCmOrSecret(kind, objectName),
+		VolumeSource: volumeSource(kind, objectName, items),
+	})
+	pm.AddVolumeMount(corev1.VolumeMount{Name: volName, MountPath: dirPath, ReadOnly: true})
+}
+
+// AddProjectedSource adds ConfigMap or Secret (or its fileName key only, if specified) to the projected Volume volName,
+// creating the Volume if not exist yet, so several objects are merged into a single directory.
+// The Volume is mounted to the dirPath of the container, if the sources are added with different dirPaths,
+// the Volume is mounted to all of them, which is not valid (see model validation)
+func AddProjectedSource(podSpec *corev1.PodSpec, container *corev1.Container, volName, dirPath string, kind ObjectKind, objectName, fileName string) {
+
+	var items []corev1.KeyToPath
+	if fileName != "" {
+		items = []corev1.KeyToPath{{Key: fileName, Path: fileName}}
+	}
+	src := corev1.VolumeProjection{}
+	if kind == ConfigMapObjectKind {
+		src.ConfigMap = &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: objectName},
+			Items:                items,
+			Optional:             ptr.To(false),
+		}
+	} else if kind == SecretObjectKind {
+		src.Secret = &corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: objectName},
+			Items:                items,
+			Optional:             ptr.To(false),
+		}
+	}
+
+	pm := PodMutator{PodSpec: podSpec, Container: container}
+	if v := pm.volume(volName); v != nil && v.Projected != nil {
+		for _, s := range v.Projected.Sources {
+			if reflect.DeepEqual(s, src) {
+				return
+			}
+		}
+		v.Projected.Sources = append(v.Projected.Sources, src)
+		pm.AddVolumeMount(corev1.VolumeMount{Name: volName, MountPath: dirPath, ReadOnly: true})
+		return
+	}
+
+	volName = pm.AddVolume(corev1.Volume{Name: volName, VolumeSource: corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{src}, DefaultMode: ptr.To(int32(420))},
+	}})
+	pm.AddVolumeMount(corev1.VolumeMount{Name: volName, MountPath: dirPath, ReadOnly: true})
+}
+
+// volumeSource makes ConfigMap or Secret VolumeSource, with all the keys of the object if items not specified
+func volumeSource(kind ObjectKind, objectName string, items []corev1.KeyToPath) corev1.VolumeSource {
 	volSrc := corev1.VolumeSource{}
 	if kind == ConfigMapObjectKind {
 		volSrc.ConfigMap = &corev1.ConfigMapVolumeSource{
 			LocalObjectReference: corev1.LocalObjectReference{Name: objectName},
 			DefaultMode:          ptr.To(int32(420)),
 			Optional:             ptr.To(false),
+			Items:                items,
 		}
 	} else if kind == SecretObjectKind {
 		volSrc.Secret = &corev1.SecretVolumeSource{
 			SecretName:  objectName,
 			DefaultMode: ptr.To(int32(420)),
 			Optional:    ptr.To(false),
+			Items:       items,
 		}
 	}
+	return volSrc
+}
 
-	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{Name: volName, VolumeSource: volSrc})
-
-	if data != nil {
-		for file := range data {
-			if fileName == "" || fileName == file {
-				vm := corev1.VolumeMount{Name: volName, MountPath: filepath.Join(mountPath, file), SubPath: file, ReadOnly: true}
-				container.VolumeMounts = append(container.VolumeMounts, vm)
-			}
-		}
-	} else {
-		vm := corev1.VolumeMount{Name: volName, MountPath: filepath.Join(mountPath, objectName), ReadOnly: true}
-		container.VolumeMounts = append(container.VolumeMounts, vm)
+func sortedKeys(data map[string]string) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
 	}
-
+	sort.Strings(keys)
+	return keys
 }
 
 func AddEnvVarsFrom(container *corev1.Container, kind ObjectKind, objectName string, varName string) {
@@ -0,0 +1,78 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateVolumeName(t *testing.T) {
+	assert.Equal(t, "cm-app-config", GenerateVolumeNameFromCmOrSecret(ConfigMapObjectKind, "app-config"))
+	assert.Equal(t, "secret-app-config", GenerateVolumeNameFromCmOrSecret(SecretObjectKind, "app-config"))
+	assert.Equal(t, "cm-my-app-config", GenerateVolumeNameFromCmOrSecret(ConfigMapObjectKind, "my.app-config"))
+
+	long := strings.Repeat("a", 70)
+	name := GenerateVolumeNameFromCmOrSecret(ConfigMapObjectKind, long)
+	assert.Equal(t, 63, len(name))
+	assert.True(t, strings.HasPrefix(name, "cm-aaa"))
+	assert.NotEqual(t, name, GenerateVolumeNameFromCmOrSecret(ConfigMapObjectKind, long+"b"))
+}
+
+func TestHashObject(t *testing.T) {
+	cm := corev1.ConfigMap{Data: map[string]string{"a": "1", "b": "2"}}
+	hash, err := HashObject(cm)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, len(hash))
+
+	same, _ := HashObject(corev1.ConfigMap{Data: map[string]string{"b": "2", "a": "1"}})
+	assert.Equal(t, hash, same)
+
+	cm.Data["a"] = "3"
+	changed, _ := HashObject(cm)
+	assert.NotEqual(t, hash, changed)
+}
+
+func TestPodMutatorVolumes(t *testing.T) {
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "c"}}}
+	pm := PodMutator{PodSpec: &podSpec, Container: &podSpec.Containers[0]}
+
+	// the same source is added once
+	vol1 := pm.AddVolume(corev1.Volume{Name: "cm-conf", VolumeSource: volumeSource(ConfigMapObjectKind, "conf", nil)})
+	vol2 := pm.AddVolume(corev1.Volume{Name: "cm-conf", VolumeSource: volumeSource(ConfigMapObjectKind, "conf", nil)})
+	assert.Equal(t, "cm-conf", vol1)
+	assert.Equal(t, vol1, vol2)
+	assert.Equal(t, 1, len(podSpec.Volumes))
+
+	// the name taken by another source is made unique
+	items := []corev1.KeyToPath{{Key: "a.yaml", Path: "a.yaml"}}
+	vol3 := pm.AddVolume(corev1.Volume{Name: "cm-conf", VolumeSource: volumeSource(ConfigMapObjectKind, "conf", items)})
+	assert.NotEqual(t, vol1, vol3)
+	assert.True(t, strings.HasPrefix(vol3, "cm-conf-"))
+	assert.Equal(t, 2, len(podSpec.Volumes))
+
+	// the same mount is added once, the same mount path of another volume is a conflict
+	pm.AddVolumeMount(corev1.VolumeMount{Name: vol1, MountPath: "/conf"})
+	pm.AddVolumeMount(corev1.VolumeMount{Name: vol1, MountPath: "/conf"})
+	assert.Equal(t, 1, len(podSpec.Containers[0].VolumeMounts))
+	assert.NoError(t, ValidateVolumeMounts(&podSpec))
+
+	pm.AddVolumeMount(corev1.VolumeMount{Name: vol3, MountPath: "/conf/"})
+	assert.ErrorContains(t, ValidateVolumeMounts(&podSpec), "mount path /conf of container c is used by both volume cm-conf and volume "+vol3)
+}
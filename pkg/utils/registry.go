@@ -0,0 +1,230 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	dockerHubRegistry = "docker.io"
+	dockerHubHost     = "registry-1.docker.io"
+
+	// DefaultRegistryTimeout limits resolving of the image, so unresponsive registry does not block the reconciliation
+	DefaultRegistryTimeout = 30 * time.Second
+)
+
+// accepted manifest media types, index (multi-arch) first so the digest is platform independent
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ImageResolver resolves container image reference to the digest (like sha256:...) of its manifest
+type ImageResolver interface {
+	Resolve(ctx context.Context, image string) (string, error)
+}
+
+// RegistryResolver is ImageResolver talking to the registry with Docker Registry HTTP API V2,
+// it supports anonymous access only (including anonymous bearer token)
+type RegistryResolver struct {
+	Client *http.Client
+	// Timeout of resolving the image, DefaultRegistryTimeout if not set
+	Timeout time.Duration
+	// PlainHTTP makes the resolver to use http instead of https, for testing with local registry
+	PlainHTTP bool
+}
+
+// ImageReference is parsed container image reference
+type ImageReference struct {
+	// Registry host, like quay.io
+	Registry string
+	// Repository path, like janus-idp/backstage-showcase
+	Repository string
+	// Tag, latest if neither tag nor digest defined
+	Tag string
+	// Digest, like sha256:...
+	Digest string
+}
+
+// ParseImageReference parses image reference of [registry/]repository[:tag][@digest] form
+func ParseImageReference(image string) (ImageReference, error) {
+	ref := ImageReference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if name == "" {
+		return ref, fmt.Errorf("invalid image reference %q", image)
+	}
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry = first
+		ref.Repository = rest
+	} else {
+		ref.Registry = dockerHubRegistry
+		ref.Repository = name
+	}
+	if ref.Registry == dockerHubRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// Resolve implements ImageResolver
+func (r *RegistryResolver) Resolve(ctx context.Context, image string) (string, error) {
+	ref, err := ParseImageReference(image)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = DefaultRegistryTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host := ref.Registry
+	if host == dockerHubRegistry {
+		host = dockerHubHost
+	}
+	scheme := "https"
+	if r.PlainHTTP {
+		scheme = "http"
+	}
+	manifestUrl := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, host, ref.Repository, ref.Tag)
+
+	resp, err := r.manifest(ctx, manifestUrl, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := r.token(ctx, resp.Header.Get("WWW-Authenticate"))
+		_ = resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to authorize to %s: %w", ref.Registry, err)
+		}
+		if resp, err = r.manifest(ctx, manifestUrl, token); err != nil {
+			return "", err
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get manifest of %s: %s", image, resp.Status)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	// not all the registries return the digest header, calculate it from the manifest then
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest of %s: %w", image, err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body)), nil
+}
+
+func (r *RegistryResolver) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return http.DefaultClient
+}
+
+func (r *RegistryResolver) manifest(ctx context.Context, manifestUrl string, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest %s: %w", manifestUrl, err)
+	}
+	return resp, nil
+}
+
+// token gets anonymous bearer token as requested by WWW-Authenticate header challenge
+func (r *RegistryResolver) token(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication %q, only anonymous bearer token is supported", scheme)
+	}
+	values := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok {
+			values[key] = strings.Trim(value, `"`)
+		}
+	}
+	realm, err := url.Parse(values["realm"])
+	if err != nil || values["realm"] == "" {
+		return "", fmt.Errorf("invalid token realm in %q", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if values[key] != "" {
+			query.Set(key, values[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get token: %s", resp.Status)
+	}
+	tokens := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	if tokens.Token != "" {
+		return tokens.Token, nil
+	}
+	return tokens.AccessToken, nil
+}
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+
+	ref, err := ParseImageReference("quay.io/janus-idp/backstage-showcase:next")
+	assert.NoError(t, err)
+	assert.Equal(t, ImageReference{Registry: "quay.io", Repository: "janus-idp/backstage-showcase", Tag: "next"}, ref)
+
+	ref, err = ParseImageReference("busybox")
+	assert.NoError(t, err)
+	assert.Equal(t, ImageReference{Registry: "docker.io", Repository: "library/busybox", Tag: "latest"}, ref)
+
+	ref, err = ParseImageReference("localhost:5000/my/image@sha256:123")
+	assert.NoError(t, err)
+	assert.Equal(t, ImageReference{Registry: "localhost:5000", Repository: "my/image", Digest: "sha256:123"}, ref)
+
+	_, err = ParseImageReference(":latest")
+	assert.Error(t, err)
+}
+
+// local stand-in registry requiring anonymous bearer token
+func newTestRegistry(t *testing.T, digestHeader bool) *httptest.Server {
+	manifest := `{"schemaVersion":2}`
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "repository:my/image:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"my-token"}`))
+		case r.Header.Get("Authorization") != "Bearer my-token":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:my/image:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/my/image/manifests/1.0":
+			assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+			if digestHeader {
+				w.Header().Set("Docker-Content-Digest", "sha256:from-header")
+			}
+			_, _ = w.Write([]byte(manifest))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv
+}
+
+func TestRegistryResolver(t *testing.T) {
+
+	srv := newTestRegistry(t, true)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	resolver := &RegistryResolver{PlainHTTP: true}
+
+	digest, err := resolver.Resolve(context.TODO(), host+"/my/image:1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:from-header", digest)
+
+	_, err = resolver.Resolve(context.TODO(), host+"/my/image:2.0")
+	assert.ErrorContains(t, err, "404")
+
+	// digest is not resolved
+	digest, err = resolver.Resolve(context.TODO(), host+"/my/image@sha256:123")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:123", digest)
+}
+
+func TestRegistryResolverNoDigestHeader(t *testing.T) {
+
+	srv := newTestRegistry(t, false)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	digest, err := (&RegistryResolver{PlainHTTP: true}).Resolve(context.TODO(), host+"/my/image:1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"schemaVersion":2}`))), digest)
+}
+
+func TestRegistryResolverTimeout(t *testing.T) {
+
+	hang := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer srv.Close()
+	defer close(hang)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	_, err := (&RegistryResolver{PlainHTTP: true, Timeout: 100 * time.Millisecond}).Resolve(context.TODO(), host+"/my/image:1.0")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -17,10 +17,14 @@ package utils
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"k8s.io/client-go/discovery"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -51,10 +55,43 @@ func GenerateRuntimeObjectName(backstageCRName string, objectType string) string
 	return fmt.Sprintf("%s-%s", backstageCRName, objectType)
 }
 
-// GenerateVolumeNameFromCmOrSecret generates volume name for mounting ConfigMap or Secret
-func GenerateVolumeNameFromCmOrSecret(cmOrSecretName string) string {
-	//return fmt.Sprintf("vol-%s", cmOrSecretName)
-	return cmOrSecretName
+// maximum length of the Volume name (DNS-1123 label)
+const maxVolumeNameLength = 63
+
+// GenerateVolumeNameFromCmOrSecret generates volume name for mounting ConfigMap or Secret,
+// prefixed with the kind, so ConfigMap and Secret of the same name do not collide.
+// The name is made DNS-1123 label compatible, the too long one is truncated and suffixed with the hash of the full name
+func GenerateVolumeNameFromCmOrSecret(kind ObjectKind, cmOrSecretName string) string {
+	prefix := "cm-"
+	if kind == SecretObjectKind {
+		prefix = "secret-"
+	}
+	name := strings.ReplaceAll(strings.ToLower(prefix+cmOrSecretName), ".", "-")
+	if len(name) <= maxVolumeNameLength {
+		return name
+	}
+	return hashedName(name, name)
+}
+
+// hashedName suffixes the name with the short hash of the seed, truncating the name to fit DNS-1123 label
+func hashedName(name string, seed string) string {
+	hash := sha256.Sum256([]byte(seed))
+	suffix := hex.EncodeToString(hash[:])[:8]
+	if len(name) > maxVolumeNameLength-len(suffix)-1 {
+		name = name[:maxVolumeNameLength-len(suffix)-1]
+	}
+	return fmt.Sprintf("%s-%s", strings.TrimRight(name, "-"), suffix)
+}
+
+// HashObject returns sha256 hash of the object serialized to JSON,
+// which is stable as long as the object does not change
+func HashObject(object interface{}) (string, error) {
+	content, err := json.Marshal(object)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize object: %w", err)
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
 }
 
 func ReadYaml(manifest []byte, object interface{}) error {
@@ -77,8 +114,10 @@ func ReadYamlFile(path string, object /*metav1.Object*/ interface{}) error {
 	return ReadYaml(b, object)
 }
 
-func DefFile(key string) string {
-	return filepath.Join(os.Getenv("LOCALBIN"), "default-config", key)
+// DefFile returns the path of default configuration file for the key,
+// in the profile subdirectory if profile is specified
+func DefFile(profile string, key string) string {
+	return filepath.Join(os.Getenv("LOCALBIN"), "default-config", profile, key)
 }
 
 func GeneratePassword(length int) (string, error) {